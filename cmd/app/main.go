@@ -4,8 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"dtf/game_draw/internal"
+	iManagers "dtf/game_draw/internal/domain/managers"
 	"dtf/game_draw/internal/domain/models"
 	iRepo "dtf/game_draw/internal/domain/repositories"
+	"dtf/game_draw/internal/managers"
 	"dtf/game_draw/internal/repositories"
 	"dtf/game_draw/internal/storage"
 	"dtf/game_draw/internal/storage/sqlite"
@@ -26,6 +28,23 @@ import (
 	"gopkg.in/telebot.v4"
 )
 
+const (
+	sessionRefreshInterval = 10 * time.Minute
+	sessionRefreshAhead    = 30 * time.Minute
+
+	// same as dtf client limiter burst, more workers would just wait for it
+	enrichWorkers = 3
+
+	raffleSyncInterval = time.Hour
+
+	// new raffles are found hourly, there is no hurry to join them
+	participationInterval = 3 * time.Hour
+
+	winsCheckInterval = 2 * time.Hour
+	// organizers add winners to results for a while, so recent results are rechecked
+	winsCheckWindow = 7 * 24 * time.Hour
+)
+
 func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
@@ -37,7 +56,7 @@ func main() {
 
 	initSlog()
 
-	deps, cleanup := initDependencies(ctx, config.DbPath)
+	deps, cleanup := initDependencies(ctx, config)
 	defer func() {
 		if err := cleanup(); err != nil {
 			slog.Error("dependencies cleanup error", "err", err)
@@ -47,14 +66,18 @@ func main() {
 	bot, err := telegram.NewBot(
 		config.TelegramToken,
 		deps.telegramSubsRepo,
-		deps.activeRafflesUseCase,
+		deps.sessionRepo,
+		deps.openRafflesUseCase,
+		deps.deliverRafflesUseCase,
+		deps.sourceRulesUseCase,
+		deps.linkDtfAccountUseCase,
 		config.TelegramAdmins,
 	)
 	if err != nil {
 		log.Fatalf("Fuck! Reason: %s", err)
 	}
 
-	schedulder := setupScheduledJobs(bot, deps.telegramSubsRepo, deps.activeRafflesUseCase)
+	schedulder := setupScheduledJobs(bot, deps)
 	defer schedulder.Shutdown()
 
 	go func() {
@@ -74,18 +97,33 @@ func main() {
 }
 
 type Dependencies struct {
-	db *sql.DB
+	db             *sql.DB
+	telegramAdmins []int64
 
 	// repos
 	telegramSubsRepo iRepo.TelegramSubscribersRepository
+	sessionRepo      iRepo.DtfSessionRepository
 	postRepo         iRepo.PostRepository
+	raffleRepo       iRepo.RaffleRepository
+	raffleWinRepo    iRepo.RaffleWinRepository
+	deliveryRepo     iRepo.RaffleDeliveryRepository
+
+	// managers
+	userManager iManagers.UserManager
 
 	// usecases
-	activeRafflesUseCase *usecases.GetActiveRafflePostsUseCase
+	activeRafflesUseCase   *usecases.GetActiveRafflePostsUseCase
+	syncRafflesUseCase     *usecases.SyncRafflesUseCase
+	openRafflesUseCase     *usecases.GetOpenRafflesUseCase
+	deliverRafflesUseCase  *usecases.DeliverRafflesUseCase
+	sourceRulesUseCase     *usecases.SourceRulesUseCase
+	linkDtfAccountUseCase  *usecases.LinkDtfAccountUseCase
+	autoParticipateUseCase *usecases.AutoParticipateUseCase
+	detectWinsUseCase      *usecases.DetectWinsUseCase
 }
 
-func initDependencies(ctx context.Context, dbPath string) (*Dependencies, func() error) {
-	db, err := sqlite.InitDB(dbPath)
+func initDependencies(ctx context.Context, config *internal.Config) (*Dependencies, func() error) {
+	db, err := sqlite.InitDB(config.DbPath)
 	if err != nil {
 		panic(fmt.Sprintf("Couldnt connect to DB. Reason: %s", err.Error()))
 	}
@@ -98,10 +136,50 @@ func initDependencies(ctx context.Context, dbPath string) (*Dependencies, func()
 
 	// repos
 	var telegramSubsRepo iRepo.TelegramSubscribersRepository = repositories.NewSqliteTelegramSubRepository(sqlProvider, transactor)
-	var postRepo iRepo.PostRepository = repositories.NewDtfPostRepository(dtfService)
+	var sessionRepo iRepo.DtfSessionRepository = repositories.NewSqliteUserSessionRepository(sqlProvider)
+	dtfTokenSources := repositories.NewDtfTokenSources(dtfService, sessionRepo)
+	var postRepo iRepo.PostRepository = repositories.NewDtfPostRepository(dtfService, dtfTokenSources)
+	var authRepo iRepo.AuthRepository = repositories.NewDtfAuthRepository(dtfService, dtfTokenSources)
+	var raffleRepo iRepo.RaffleRepository = repositories.NewSqliteRaffleRepository(sqlProvider, transactor)
+	var raffleWinRepo iRepo.RaffleWinRepository = repositories.NewSqliteRaffleWinRepository(sqlProvider)
+	var deliveryRepo iRepo.RaffleDeliveryRepository = repositories.NewSqliteRaffleDeliveryRepository(sqlProvider, transactor)
+	var watermarkRepo iRepo.WatermarkRepository = repositories.NewSqliteWatermarkRepository(sqlProvider)
+	var sourceRuleRepo iRepo.SourceRuleRepository = repositories.NewSqliteSourceRuleRepository(sqlProvider)
+	var participationRepo iRepo.RaffleParticipationRepository = repositories.NewSqliteRaffleParticipationRepository(sqlProvider)
+
+	// managers
+	var userManager iManagers.UserManager = managers.NewUserSessionManager(sessionRepo, authRepo)
 
 	// use cases
-	activeRafflesUseCase := usecases.NewGetActiveRafflePostsUseCase(postRepo)
+	activeRafflesUseCase := usecases.NewGetActiveRafflePostsUseCase(
+		postRepo,
+		usecases.WithContentEnrichment(enrichWorkers),
+		usecases.WithDiscoveryQueries(config.DiscoveryQueries...),
+	)
+	syncRafflesUseCase := usecases.NewSyncRafflesUseCase(activeRafflesUseCase, postRepo, raffleRepo, watermarkRepo)
+	openRafflesUseCase := usecases.NewGetOpenRafflesUseCase(raffleRepo, sourceRuleRepo)
+	deliverRafflesUseCase := usecases.NewDeliverRafflesUseCase(raffleRepo, deliveryRepo, sourceRuleRepo)
+	sourceRulesUseCase := usecases.NewSourceRulesUseCase(sourceRuleRepo)
+	linkDtfAccountUseCase := usecases.NewLinkDtfAccountUseCase(authRepo, sessionRepo)
+	likeAndPostUseCase := usecases.NewLikeAndPostToRafflePostUseCase(postRepo, userManager)
+	autoParticipateUseCase := usecases.NewAutoParticipateUseCase(
+		sessionRepo,
+		authRepo,
+		raffleRepo,
+		postRepo,
+		participationRepo,
+		sourceRuleRepo,
+		userManager,
+		likeAndPostUseCase,
+	)
+	detectWinsUseCase := usecases.NewDetectWinsUseCase(
+		sessionRepo,
+		authRepo,
+		raffleRepo,
+		postRepo,
+		raffleWinRepo,
+		userManager,
+	)
 
 	// function to clean all generated shit
 	cleanup := func() error {
@@ -122,11 +200,26 @@ func initDependencies(ctx context.Context, dbPath string) (*Dependencies, func()
 	}
 
 	return &Dependencies{
-		db:               db,
+		db:             db,
+		telegramAdmins: config.TelegramAdmins,
+
 		telegramSubsRepo: telegramSubsRepo,
+		sessionRepo:      sessionRepo,
 		postRepo:         postRepo,
-
-		activeRafflesUseCase: activeRafflesUseCase,
+		raffleRepo:       raffleRepo,
+		raffleWinRepo:    raffleWinRepo,
+		deliveryRepo:     deliveryRepo,
+
+		userManager: userManager,
+
+		activeRafflesUseCase:   activeRafflesUseCase,
+		syncRafflesUseCase:     syncRafflesUseCase,
+		openRafflesUseCase:     openRafflesUseCase,
+		deliverRafflesUseCase:  deliverRafflesUseCase,
+		sourceRulesUseCase:     sourceRulesUseCase,
+		linkDtfAccountUseCase:  linkDtfAccountUseCase,
+		autoParticipateUseCase: autoParticipateUseCase,
+		detectWinsUseCase:      detectWinsUseCase,
 	}, cleanup
 }
 
@@ -137,11 +230,7 @@ func initSlog() {
 	slog.SetDefault(logger)
 }
 
-func setupScheduledJobs(
-	bot *telebot.Bot,
-	telegramSubRepo iRepo.TelegramSubscribersRepository,
-	activeRaffleUseCase *usecases.GetActiveRafflePostsUseCase,
-) gocron.Scheduler {
+func setupScheduledJobs(bot *telebot.Bot, deps *Dependencies) gocron.Scheduler {
 	location, err := time.LoadLocation("Europe/Moscow")
 	if err != nil {
 		log.Fatalf("Cant load location: %v\n", err)
@@ -158,7 +247,7 @@ func setupScheduledJobs(
 			gocron.NewAtTime(14, 0, 0),
 		)),
 		gocron.NewTask(func(ctx context.Context) {
-			users, err := telegramSubRepo.GetAll(ctx)
+			users, err := deps.telegramSubsRepo.GetAll(ctx)
 			if err != nil {
 				slog.Error("Cron job error", "error", err)
 			}
@@ -167,43 +256,193 @@ func setupScheduledJobs(
 				return
 			}
 
-			prevDay := time.Now().In(location).AddDate(0, 0, -1)
-			raffles, err := activeRaffleUseCase.Execute(ctx, prevDay)
+			// catalog is kept fresh by the sync job, only it writes there,
+			// every subscriber gets only raffles they haven't seen yet
+			for _, user := range users {
+				sendPendingRaffles(ctx, bot, deps, user.TelegramId)
+			}
+		}),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		slog.Error("couldn't setup scheduled job", "err", err)
+	}
+
+	// DTF tokens are refreshed ahead of expiration,
+	// so they never die in the middle of other jobs
+	_, err = s.NewJob(
+		gocron.DurationJob(sessionRefreshInterval),
+		gocron.NewTask(func(ctx context.Context) {
+			report, err := deps.userManager.RefreshExpiring(ctx, sessionRefreshAhead)
 			if err != nil {
-				// TODO: retry logic
-				slog.Error("Cant load raffles from DTF", "error", err)
+				slog.Error("Sessions refresh error", "error", err)
 				return
 			}
-			// dont bother users when no active raffles
-			if len(raffles) == 0 {
+			if len(report.Refreshed)+len(report.Failed)+len(report.NeedsRelogin) == 0 {
 				return
 			}
+			slog.Info(
+				"Sessions refreshed",
+				"refreshed", report.Refreshed,
+				"failed", report.Failed,
+				"needs_relogin", report.NeedsRelogin,
+			)
+		}),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+		gocron.WithStartAt(gocron.WithStartImmediately()),
+	)
+	if err != nil {
+		slog.Error("couldn't setup session refresh job", "err", err)
+	}
+
+	// raffle catalog is kept fresh, so bot can answer without going to DTF
+	_, err = s.NewJob(
+		gocron.DurationJob(raffleSyncInterval),
+		gocron.NewTask(func(ctx context.Context) {
+			syncRaffles(ctx, bot, deps)
+		}),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+		gocron.WithStartAt(gocron.WithStartImmediately()),
+	)
+	if err != nil {
+		slog.Error("couldn't setup raffles sync job", "err", err)
+	}
 
-			// ok, lets send this shit to users
-			text := prepareTelegramText(raffles)
-			if err := telegram_utils.BroadcastWithRetries(
-				ctx,
-				bot,
-				text,
-				models.TelegramSessionsToIds(users),
-			); err != nil {
-				slog.Error("Error sending raffles by schedule", "err", err)
+	// opted-in accounts join raffles, every account owner gets a summary,
+	// admins get it if account is not linked to telegram
+	_, err = s.NewJob(
+		gocron.DurationJob(participationInterval),
+		gocron.NewTask(func(ctx context.Context) {
+			reports, err := deps.autoParticipateUseCase.Execute(ctx)
+			if err != nil {
+				slog.Error("Auto participation error", "error", err)
 			}
+			for _, report := range reports {
+				slog.Info(
+					"Auto participation done",
+					"email", report.Email,
+					"joined", len(report.Joined),
+					"skipped", len(report.Skipped),
+					"failed", len(report.Failed),
+				)
+				recipients := deps.telegramAdmins
+				if report.TelegramId != 0 {
+					recipients = []int64{report.TelegramId}
+				}
+				if err := telegram_utils.BroadcastWithRetries(
+					ctx,
+					bot,
+					telegram_utils.ParticipationReportToTelegramText(report),
+					recipients,
+				); err != nil {
+					slog.Error("Error sending participation report", "err", err, "email", report.Email)
+				}
+			}
+		}),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		slog.Error("couldn't setup auto participation job", "err", err)
+	}
 
+	// our accounts in raffle results, winner is told personally,
+	// admins are told if account is not linked to telegram
+	_, err = s.NewJob(
+		gocron.DurationJob(winsCheckInterval),
+		gocron.NewTask(func(ctx context.Context) {
+			wins, err := deps.detectWinsUseCase.Execute(ctx, time.Now().Add(-winsCheckWindow))
+			if err != nil {
+				slog.Error("Wins check error", "error", err)
+			}
+			for _, win := range wins {
+				recipients := deps.telegramAdmins
+				if win.TelegramId != 0 {
+					recipients = []int64{win.TelegramId}
+				}
+				if err := telegram_utils.BroadcastWithRetries(
+					ctx,
+					bot,
+					telegram_utils.WinToTelegramText(win),
+					recipients,
+				); err != nil {
+					slog.Error("Error sending win notification", "err", err, "email", win.Email)
+				}
+			}
 		}),
 		gocron.WithSingletonMode(gocron.LimitModeReschedule),
 	)
 	if err != nil {
-		slog.Error("couldn't setup scheduled job", "err", err)
+		slog.Error("couldn't setup wins check job", "err", err)
 	}
 	return s
 }
 
-func prepareTelegramText(posts []models.Post) string {
-	text := telegram_utils.ManyPostsToTelegramText(posts, false)
+// syncRaffles updates raffle catalog and tells subscribers
+// about results of raffles they were told about
+func syncRaffles(ctx context.Context, bot *telebot.Bot, deps *Dependencies) {
+	report, err := deps.syncRafflesUseCase.Execute(ctx)
+	if err != nil {
+		// dtf client already retried temporary failures
+		slog.Error("Raffles sync error", "error", err)
+		return
+	}
+	slog.Info(
+		"Raffles synced",
+		"new", len(report.New),
+		"changed", len(report.Changed),
+		"results", len(report.ResultsLinked),
+	)
+
+	recipients, err := deps.deliverRafflesUseCase.ResultsRecipients(ctx, report.ResultsLinked)
+	if err != nil {
+		slog.Error("Cant load recipients for results", "error", err)
+		return
+	}
+	for telegramId, raffles := range recipients {
+		if err := telegram_utils.BroadcastWithRetries(
+			ctx,
+			bot,
+			telegram_utils.ResultsToTelegramText(raffles),
+			[]int64{telegramId},
+		); err != nil {
+			slog.Error("Error sending raffle results", "err", err, "telegram_id", telegramId)
+		}
+	}
+}
+
+// sendPendingRaffles sends raffles subscriber haven't got yet,
+// they are marked delivered only after successful send
+func sendPendingRaffles(ctx context.Context, bot *telebot.Bot, deps *Dependencies, telegramId int64) {
+	raffles, err := deps.deliverRafflesUseCase.Pending(ctx, telegramId)
+	if err != nil {
+		slog.Error("Cant load pending raffles", "error", err, "telegram_id", telegramId)
+		return
+	}
+	// dont bother users when nothing new
+	if len(raffles) == 0 {
+		return
+	}
+
+	if err := telegram_utils.BroadcastWithRetries(
+		ctx,
+		bot,
+		prepareTelegramText(raffles),
+		[]int64{telegramId},
+	); err != nil {
+		slog.Error("Error sending raffles by schedule", "err", err, "telegram_id", telegramId)
+		return
+	}
+
+	if err := deps.deliverRafflesUseCase.MarkDelivered(ctx, telegramId, raffles); err != nil {
+		slog.Error("Cant mark raffles as delivered", "error", err, "telegram_id", telegramId)
+	}
+}
+
+func prepareTelegramText(raffles []models.Raffle) string {
+	text := telegram_utils.ManyPostsToTelegramText(raffles, false)
 	if telegram_utils.IsTooLongForTelegramPost(text) {
 		// do the shorten version
-		text = telegram_utils.ManyPostsToTelegramText(posts, true)
+		text = telegram_utils.ManyPostsToTelegramText(raffles, true)
 	}
 	return text
 }
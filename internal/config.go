@@ -13,6 +13,8 @@ type Config struct {
 	DbPath         string
 	TelegramToken  string
 	TelegramAdmins []int64
+	// raffle search queries, empty means defaults
+	DiscoveryQueries []string
 }
 
 const configPath = ".env"
@@ -32,9 +34,10 @@ func NewConfig() (*Config, error) {
 	telegramToken := env["TELEGRAM_TOKEN"]
 
 	config := &Config{
-		DbPath:         sqlitePath,
-		TelegramToken:  telegramToken,
-		TelegramAdmins: telegramAdmins,
+		DbPath:           sqlitePath,
+		TelegramToken:    telegramToken,
+		TelegramAdmins:   telegramAdmins,
+		DiscoveryQueries: envToList(env["DISCOVERY_QUERIES"]),
 	}
 
 	err = validateConfig(*config)
@@ -91,3 +94,17 @@ func envToTelegramAdmins(envStr string) ([]int64, error) {
 
 	return result, nil
 }
+
+// envToList splits comma separated value, empty items are skipped
+func envToList(envStr string) []string {
+	result := make([]string, 0)
+	for v := range strings.SplitSeq(envStr, ",") {
+		trimmedV := strings.TrimSpace(v)
+		if trimmedV == "" {
+			continue
+		}
+		result = append(result, trimmedV)
+	}
+
+	return result
+}
@@ -10,6 +10,16 @@ var (
 // Session Errors
 var (
 	ErrUserSessionNotFound = errors.New("usersession not found")
+	ErrSessionExpired      = errors.New("dtf session expired, login required")
+	ErrUnauthorized        = errors.New("dtf access denied")
+	ErrSessionConflict     = errors.New("dtf session was changed concurrently")
+)
+
+// DTF Errors
+var (
+	ErrDtfRateLimited = errors.New("dtf rate limit exceeded")
+	ErrDtfUnavailable = errors.New("dtf is unavailable")
+	ErrPostNotFound   = errors.New("dtf post not found")
 )
 
 // Telegram Errors
@@ -17,3 +27,19 @@ var (
 	ErrTelegramUserNotFound = errors.New("telegram user not found")
 	ErrTelegramUserExists   = errors.New("telegram user already exists")
 )
+
+// Raffle Errors
+var (
+	ErrRaffleNotFound = errors.New("raffle not found")
+	ErrRaffleTooRisky = errors.New("raffle looks like a scam")
+)
+
+// Source Rule Errors
+var (
+	ErrSourceRuleNotFound = errors.New("source rule not found")
+)
+
+// Sync Errors
+var (
+	ErrWatermarkNotFound = errors.New("watermark not found")
+)
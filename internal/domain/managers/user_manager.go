@@ -3,9 +3,15 @@ package managers
 import (
 	"context"
 	"dtf/game_draw/internal/domain/models"
+	"time"
 )
 
 type UserManager interface {
 	BuildSession(ctx context.Context, email string) (models.DtfUserSession, error)
 	EmailLogin(ctx context.Context, email, password string) (models.DtfUserSession, error)
+
+	// session maintenance
+	RefreshExpiring(ctx context.Context, ahead time.Duration) (models.SessionRefreshReport, error)
+	SessionHealth(ctx context.Context, email string) (models.SessionHealth, error)
+	SessionsHealth(ctx context.Context) ([]models.SessionHealth, error)
 }
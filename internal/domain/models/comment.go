@@ -0,0 +1,71 @@
+package models
+
+import (
+	"dtf/game_draw/pkg/dtfapi"
+	"strings"
+	"time"
+
+	"github.com/k3a/html2text"
+)
+
+type Comment struct {
+	Id       int
+	PostId   int64
+	Author   DtfUserInfo
+	Text     string // cleaned from html
+	HtmlText string
+	ReplyTo  int // if not 0 - comment is a reply to that comment
+	Level    int
+	Date     time.Time
+	Likes    int
+}
+
+func (c Comment) IsReply() bool {
+	return c.ReplyTo != 0
+}
+
+func FromDtfComment(comment dtfapi.Comment) Comment {
+	return Comment{
+		Id:     comment.Id,
+		PostId: int64(comment.PostId),
+		Author: DtfUserInfo{
+			Id:   comment.Author.Id,
+			Name: comment.Author.Name,
+			Url:  comment.Author.Url,
+		},
+		Text:     html2text.HTML2Text(comment.HtmlText),
+		HtmlText: comment.HtmlText,
+		ReplyTo:  comment.ReplyTo,
+		Level:    comment.Level,
+		Date:     comment.Date,
+		Likes:    comment.Likes,
+	}
+}
+
+// HasCommentFrom checks if user left a comment containing text.
+// Empty text matches any comment of the user.
+func HasCommentFrom(comments []Comment, userId int, text string) bool {
+	text = strings.ToLower(text)
+	for _, comment := range comments {
+		if comment.Author.Id != userId {
+			continue
+		}
+		if strings.Contains(strings.ToLower(comment.Text), text) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CommentsFrom returns comments left by the author, e.g. organizer replies.
+func CommentsFrom(comments []Comment, authorId int) []Comment {
+	var result []Comment
+	for _, comment := range comments {
+		if comment.Author.Id == authorId {
+			result = append(result, comment)
+		}
+	}
+
+	return result
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/k3a/html2text"
 )
@@ -27,7 +28,8 @@ type DataHeader struct {
 func (d DataHeader) Type() string { return "header" }
 
 type DataList struct {
-	List []string
+	List    []string
+	Ordered bool
 }
 
 func (dl DataList) Type() string { return "list" }
@@ -45,7 +47,11 @@ func (dl DataList) String() string {
 			b.WriteByte('\n')
 		}
 		cleandText := html2text.HTML2Text(item)
-		b.WriteString("- ")
+		if dl.Ordered {
+			_, _ = fmt.Fprintf(&b, "%d. ", i+1)
+		} else {
+			b.WriteString("- ")
+		}
 		b.WriteString(cleandText)
 		b.WriteByte('\n')
 	}
@@ -53,13 +59,91 @@ func (dl DataList) String() string {
 	return b.String()
 }
 
+type DataImage struct {
+	Url     string
+	Width   int
+	Height  int
+	Caption string
+}
+
+func (d DataImage) Type() string { return "image" }
+
+type DataGallery struct {
+	Images []DataImage
+}
+
+func (d DataGallery) Type() string { return "gallery" }
+
+type DataQuote struct {
+	HtmlText string
+	Author   string
+}
+
+func (d DataQuote) Type() string { return "quote" }
+
+type DataEmbed struct {
+	Service string
+	Url     string
+	Title   string
+}
+
+func (d DataEmbed) Type() string { return "embed" }
+
+type DataLink struct {
+	Url         string
+	Title       string
+	Description string
+}
+
+func (d DataLink) Type() string { return "link" }
+
+type DataSpoiler struct {
+	Title    string
+	HtmlText string
+}
+
+func (d DataSpoiler) Type() string { return "spoiler" }
+
+type DataCode struct {
+	Text string
+	Lang string
+}
+
+func (d DataCode) Type() string { return "code" }
+
+type DataDelimiter struct{}
+
+func (d DataDelimiter) Type() string { return "delimiter" }
+
+// DataUnknown is a block we can't parse yet. Raw contains original json.
+type DataUnknown struct {
+	BlockType string
+	Raw       []byte
+}
+
+func (d DataUnknown) Type() string { return d.BlockType }
+
+type Subsite struct {
+	Id   int
+	Name string
+	Url  string
+}
+
 type Post struct {
-	Id        int64
-	Title     string
-	Text      string // cleaned from html and concatenated text
-	Uri       string
-	Blocks    []DataBlock
-	RepliedTo *int // if not nil - post is a reply to that post
+	Id          int64
+	Title       string
+	Text        string // cleaned from html and concatenated text
+	Uri         string
+	Blocks      []DataBlock
+	RepliedTo   *int // if not nil - post is a reply to that post
+	PublishedAt time.Time
+	Author      DtfUserInfo
+	Subsite     Subsite
+	Likes       int
+	Comments    int
+	Views       int
+	Reposts     int
+	CoverUrl    string
 }
 
 func (p Post) IsReply() bool {
@@ -100,12 +184,70 @@ func FromDtfPost(post dtfapi.BlogPost) (Post, error) {
 
 		case dtfapi.DataList:
 			data := DataList{
-				List: b.Items(),
+				List:    b.Items(),
+				Ordered: b.IsOrdered(),
 			}
 			cleanedText := data.String()
 			cleanedTextBuilder.WriteString(cleanedText)
 			blocks = append(blocks, data)
 
+		case dtfapi.DataImage:
+			blocks = append(blocks, imageFromDtf(b))
+
+		case dtfapi.DataGallery:
+			images := make([]DataImage, 0, len(b.Images))
+			for _, image := range b.Images {
+				images = append(images, imageFromDtf(image))
+			}
+			blocks = append(blocks, DataGallery{Images: images})
+
+		case dtfapi.DataQuote:
+			data := DataQuote{
+				HtmlText: b.HtmlText,
+				Author:   b.Author,
+			}
+			cleanedTextBuilder.WriteString(html2text.HTML2Text(b.HtmlText) + "\n")
+			blocks = append(blocks, data)
+
+		case dtfapi.DataEmbed:
+			blocks = append(blocks, DataEmbed{
+				Service: b.Service,
+				Url:     b.Url,
+				Title:   b.Title,
+			})
+
+		case dtfapi.DataLink:
+			data := DataLink{
+				Url:         b.Url,
+				Title:       b.Title,
+				Description: b.Description,
+			}
+			cleanedTextBuilder.WriteString(b.Url + "\n")
+			blocks = append(blocks, data)
+
+		case dtfapi.DataSpoiler:
+			data := DataSpoiler{
+				Title:    b.Title,
+				HtmlText: b.HtmlText,
+			}
+			cleanedTextBuilder.WriteString(html2text.HTML2Text(b.HtmlText) + "\n")
+			blocks = append(blocks, data)
+
+		case dtfapi.DataCode:
+			blocks = append(blocks, DataCode{
+				Text: b.Text,
+				Lang: b.Lang,
+			})
+
+		case dtfapi.DataDelimiter:
+			blocks = append(blocks, DataDelimiter{})
+
+		case dtfapi.DataUnknown:
+			blocks = append(blocks, DataUnknown{
+				BlockType: b.BlockType,
+				Raw:       b.Raw,
+			})
+
 		default:
 			// do nothing
 			continue
@@ -113,11 +255,37 @@ func FromDtfPost(post dtfapi.BlogPost) (Post, error) {
 	}
 
 	return Post{
-		Id:        int64(post.Id),
-		Title:     post.Title,
-		Uri:       post.Uri,
-		Text:      cleanedTextBuilder.String(),
-		Blocks:    blocks,
-		RepliedTo: post.RepliedTo,
+		Id:          int64(post.Id),
+		Title:       post.Title,
+		Uri:         post.Uri,
+		Text:        cleanedTextBuilder.String(),
+		Blocks:      blocks,
+		RepliedTo:   post.RepliedTo,
+		PublishedAt: post.PublishedAt,
+		Author: DtfUserInfo{
+			Id:        post.Author.Id,
+			Name:      post.Author.Name,
+			Url:       post.Author.Url,
+			CreatedAt: post.Author.CreatedAt,
+		},
+		Subsite: Subsite{
+			Id:   post.Subsite.Id,
+			Name: post.Subsite.Name,
+			Url:  post.Subsite.Url,
+		},
+		Likes:    post.Likes,
+		Comments: post.Comments,
+		Views:    post.Views,
+		Reposts:  post.Reposts,
+		CoverUrl: post.CoverUrl,
 	}, nil
 }
+
+func imageFromDtf(image dtfapi.DataImage) DataImage {
+	return DataImage{
+		Url:     image.Url,
+		Width:   image.Width,
+		Height:  image.Height,
+		Caption: image.Caption,
+	}
+}
@@ -0,0 +1,363 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+type RaffleKind string
+
+const (
+	NotRaffle     RaffleKind = "not_raffle"
+	RaffleActive  RaffleKind = "active"
+	RaffleResults RaffleKind = "results"
+)
+
+// RaffleClassification explains why post was classified that way
+type RaffleClassification struct {
+	Kind         RaffleKind
+	RaffleScore  int
+	ResultsScore int
+	Reasons      []string // rules which fired, e.g. `title "итог*" +15 results`
+}
+
+type RaffleState string
+
+const (
+	RaffleStateNew              RaffleState = "new"         // just discovered
+	RaffleStateActive           RaffleState = "active"      // seen before, still going
+	RaffleStateEndingSoon       RaffleState = "ending_soon" // deadline is near
+	RaffleStateEnded            RaffleState = "ended"       // deadline passed, no results yet
+	RaffleStateResultsPublished RaffleState = "results"     // organizer published results
+	RaffleStateAbandoned        RaffleState = "abandoned"   // results weren't published in time
+)
+
+// IsOpen is true while users still can participate
+func (s RaffleState) IsOpen() bool {
+	return s == RaffleStateNew || s == RaffleStateActive || s == RaffleStateEndingSoon
+}
+
+// Raffle is a discovered raffle post with everything we know about it
+type Raffle struct {
+	Post
+	MatchedQueries []string // search queries which found the post
+	Classification RaffleClassification
+	EndsAt         Deadline
+	Conditions     RaffleConditions
+	Prizes         RafflePrizes
+	Organizer      OrganizerReputation
+	Risk           RaffleRisk
+
+	// catalog fields, filled by RaffleRepository
+	State          RaffleState
+	FirstSeenAt    time.Time
+	StateChangedAt time.Time
+	Results        ResultsPost
+}
+
+// ResultsPost is organizer post with raffle results
+type ResultsPost struct {
+	PostId      int64 // 0 if results are not published
+	Title       string
+	Uri         string
+	Text        string
+	PublishedAt time.Time
+}
+
+func (r ResultsPost) IsPublished() bool {
+	return r.PostId != 0
+}
+
+func ResultsFromPost(post Post) ResultsPost {
+	return ResultsPost{
+		PostId:      post.Id,
+		Title:       post.Title,
+		Uri:         post.Uri,
+		Text:        post.Text,
+		PublishedAt: post.PublishedAt,
+	}
+}
+
+// RaffleSyncReport is a result of catalog update
+type RaffleSyncReport struct {
+	New           []Raffle
+	Changed       []Raffle // raffles which state was changed
+	ResultsLinked []Raffle // raffles which results were found, with Results filled
+}
+
+type DeadlineConfidence int
+
+const (
+	DeadlineUnknown DeadlineConfidence = iota
+	DeadlineLow                        // relative phrase or date without context
+	DeadlineMedium                     // date near end marker like "до" or "итоги", or with time
+	DeadlineHigh                       // date and time near end marker
+)
+
+func (c DeadlineConfidence) String() string {
+	switch c {
+	case DeadlineLow:
+		return "low"
+	case DeadlineMedium:
+		return "medium"
+	case DeadlineHigh:
+		return "high"
+	default:
+		return "unknown"
+	}
+}
+
+// Deadline is raffle end extracted from post text
+type Deadline struct {
+	Time       time.Time
+	Confidence DeadlineConfidence
+	DateOnly   bool   // time wasn't mentioned, Time is end of that day
+	Source     string // text fragment deadline was parsed from
+}
+
+func (d Deadline) IsKnown() bool {
+	return d.Confidence != DeadlineUnknown
+}
+
+type ExternalService string
+
+const (
+	ServiceSteam    ExternalService = "steam"
+	ServiceTelegram ExternalService = "telegram"
+	ServiceDiscord  ExternalService = "discord"
+	ServiceVk       ExternalService = "vk"
+	ServiceYoutube  ExternalService = "youtube"
+	ServiceTwitch   ExternalService = "twitch"
+	ServiceOther    ExternalService = "other"
+)
+
+// ExternalAction is something to do outside of DTF, e.g. join Steam group
+type ExternalAction struct {
+	Service ExternalService
+	Url     string
+}
+
+// RaffleConditions is what organizer asked participants to do
+type RaffleConditions struct {
+	Like            bool
+	Comment         bool
+	CommentText     string // exact comment text, empty means anything
+	SubscribeAuthor bool
+	Repost          bool
+	External        []ExternalAction
+	MinAccountDays  int // 0 means no requirement
+	MinKarma        int // 0 means no requirement
+}
+
+// IsEmpty is true when nothing was recognized
+func (c RaffleConditions) IsEmpty() bool {
+	return !c.Like && !c.Comment && !c.SubscribeAuthor && !c.Repost &&
+		len(c.External) == 0 && c.MinAccountDays == 0 && c.MinKarma == 0
+}
+
+// ManualActions lists conditions which can't be done automatically
+func (c RaffleConditions) ManualActions() []string {
+	var actions []string
+	if c.SubscribeAuthor {
+		actions = append(actions, "подписаться на автора")
+	}
+	if c.Repost {
+		actions = append(actions, "сделать репост")
+	}
+	for _, action := range c.External {
+		if action.Url == "" {
+			actions = append(actions, string(action.Service))
+			continue
+		}
+		actions = append(actions, fmt.Sprintf("%s: %s", action.Service, action.Url))
+	}
+	if c.MinAccountDays > 0 {
+		actions = append(actions, fmt.Sprintf("аккаунт старше %d дн.", c.MinAccountDays))
+	}
+	if c.MinKarma > 0 {
+		actions = append(actions, fmt.Sprintf("карма от %d", c.MinKarma))
+	}
+	return actions
+}
+
+// Participation is what was done for raffle on behalf of user
+type Participation struct {
+	Liked         bool
+	Commented     bool
+	CommentText   string
+	ManualActions []string // conditions user has to do on their own
+}
+
+type ParticipationStatus string
+
+const (
+	ParticipationJoined  ParticipationStatus = "joined"
+	ParticipationSkipped ParticipationStatus = "skipped"
+	// something was done before error or attempts are exhausted, so it is not retried
+	ParticipationFailed ParticipationStatus = "failed"
+	// nothing was done because of error, the next run tries again
+	ParticipationRetrying ParticipationStatus = "retrying"
+)
+
+// RaffleParticipation is automatic participation of account in raffle
+type RaffleParticipation struct {
+	Raffle        Raffle
+	Email         string
+	Status        ParticipationStatus
+	Participation Participation
+	Reason        string // why skipped or failed
+	CreatedAt     time.Time
+}
+
+// ParticipationReport summarizes one participation run of account
+type ParticipationReport struct {
+	Email      string
+	TelegramId int64 // linked telegram user, 0 if none
+	Joined     []RaffleParticipation
+	Skipped    []RaffleParticipation
+	Failed     []RaffleParticipation
+	Error      string // account couldn't participate at all
+}
+
+func (r ParticipationReport) IsEmpty() bool {
+	return len(r.Joined)+len(r.Skipped)+len(r.Failed) == 0 && r.Error == ""
+}
+
+type PrizeKind string
+
+const (
+	PrizeGame         PrizeKind = "game"
+	PrizeKey          PrizeKind = "key"
+	PrizeGiftCard     PrizeKind = "gift_card"
+	PrizeSubscription PrizeKind = "subscription"
+	PrizeMerch        PrizeKind = "merch"
+	PrizeHardware     PrizeKind = "hardware"
+)
+
+type Platform string
+
+const (
+	PlatformPC          Platform = "pc"
+	PlatformPlayStation Platform = "playstation"
+	PlatformXbox        Platform = "xbox"
+	PlatformNintendo    Platform = "nintendo"
+	PlatformMobile      Platform = "mobile"
+)
+
+func (p Platform) IsConsole() bool {
+	return p == PlatformPlayStation || p == PlatformXbox || p == PlatformNintendo
+}
+
+// RafflePrizes is what is given away
+type RafflePrizes struct {
+	Kinds     []PrizeKind
+	Games     []string // game names found in text
+	Platforms []Platform
+	Winners   int // 0 if unknown
+}
+
+// IsConsoleOnly is true if only console platforms were found
+func (p RafflePrizes) IsConsoleOnly() bool {
+	if len(p.Platforms) == 0 {
+		return false
+	}
+	for _, platform := range p.Platforms {
+		if !platform.IsConsole() {
+			return false
+		}
+	}
+	return true
+}
+
+// RaffleFilter selects raffles by prizes.
+// Raffles without detected platforms or kinds are not filtered out,
+// because extraction is a guess.
+type RaffleFilter struct {
+	Platforms       []Platform  // empty means any
+	Kinds           []PrizeKind // empty means any
+	SkipConsoleOnly bool
+	MinTrust        int // organizers without history are not filtered out
+}
+
+func (f RaffleFilter) Match(raffle Raffle) bool {
+	if f.MinTrust > 0 && raffle.Organizer.IsKnown() && raffle.Organizer.Score < f.MinTrust {
+		return false
+	}
+	prizes := raffle.Prizes
+	if f.SkipConsoleOnly && prizes.IsConsoleOnly() {
+		return false
+	}
+	if len(f.Platforms) > 0 && len(prizes.Platforms) > 0 &&
+		!slices.ContainsFunc(prizes.Platforms, func(p Platform) bool { return slices.Contains(f.Platforms, p) }) {
+		return false
+	}
+	if len(f.Kinds) > 0 && len(prizes.Kinds) > 0 &&
+		!slices.ContainsFunc(prizes.Kinds, func(k PrizeKind) bool { return slices.Contains(f.Kinds, k) }) {
+		return false
+	}
+	return true
+}
+
+func (f RaffleFilter) Apply(raffles []Raffle) []Raffle {
+	var result []Raffle
+	for _, raffle := range raffles {
+		if f.Match(raffle) {
+			result = append(result, raffle)
+		}
+	}
+	return result
+}
+
+// RiskLevel tells how likely raffle is a scam
+type RiskLevel int
+
+const (
+	RiskNone RiskLevel = iota
+	RiskSuspicious
+	RiskHigh
+)
+
+// RaffleRisk explains why raffle looks like a scam
+type RaffleRisk struct {
+	Level   RiskLevel
+	Score   int
+	Reasons []string // human readable, e.g. "сокращённая ссылка bit.ly"
+}
+
+// IsRisky is true if users should be warned
+func (r RaffleRisk) IsRisky() bool {
+	return r.Level >= RiskSuspicious
+}
+
+// OrganizerStats is raffle history of DTF author in the catalog
+type OrganizerStats struct {
+	Raffles         int // all stored raffles
+	Finished        int // raffles with results and ended ones nobody waits results for
+	WithResults     int
+	AvgResultsDelay time.Duration // from raffle end to results post
+}
+
+// OrganizerReputation tells if organizer picks winners
+type OrganizerReputation struct {
+	Stats OrganizerStats
+	Score int // 0..100, meaningful only if known
+}
+
+// IsKnown is false until organizer has finished raffles
+func (r OrganizerReputation) IsKnown() bool {
+	return r.Stats.Finished > 0
+}
+
+// RaffleWin is our account found among raffle winners
+type RaffleWin struct {
+	RafflePostId int64
+	RaffleTitle  string
+	Email        string // account which won
+	DtfUser      DtfUserInfo
+	TelegramId   int64  // linked telegram user, 0 if none
+	Fragment     string // text where winner was found
+	NameOnly     bool   // found by plain name, it may be a namesake
+	Uri          string // results post or comment link
+	FoundAt      time.Time
+}
@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// SourceKind is what source rule is about
+type SourceKind string
+
+const (
+	SourceAuthor  SourceKind = "author"
+	SourceSubsite SourceKind = "subsite"
+)
+
+// SourceMode says whether posts of the source are shown
+type SourceMode string
+
+const (
+	SourceAllow SourceMode = "allow"
+	SourceDeny  SourceMode = "deny"
+)
+
+// GlobalRulesOwner owns rules made by admins, they apply to everyone
+const GlobalRulesOwner int64 = 0
+
+// SourceRule allows or denies raffles of DTF author or subsite
+type SourceRule struct {
+	Owner     int64 // telegram id, GlobalRulesOwner for admin rules
+	Kind      SourceKind
+	SourceId  int
+	Mode      SourceMode
+	CreatedAt time.Time
+}
+
+func (r SourceRule) IsGlobal() bool {
+	return r.Owner == GlobalRulesOwner
+}
+
+func (r SourceRule) matches(post Post) bool {
+	switch r.Kind {
+	case SourceAuthor:
+		return post.Author.Id == r.SourceId
+	case SourceSubsite:
+		return post.Subsite.Id == r.SourceId
+	default:
+		return false
+	}
+}
+
+// SourceRules are global and personal rules of one subscriber
+type SourceRules []SourceRule
+
+// Allows decides by the most specific matching rule:
+// personal rules win over global ones, author rules over subsite ones.
+// So allow rule is an exception, e.g. trusted author in denied subsite.
+// Posts without matching rules are allowed.
+func (rules SourceRules) Allows(post Post) bool {
+	for _, global := range []bool{false, true} {
+		for _, kind := range []SourceKind{SourceAuthor, SourceSubsite} {
+			for _, rule := range rules {
+				if rule.IsGlobal() == global && rule.Kind == kind && rule.matches(post) {
+					return rule.Mode == SourceAllow
+				}
+			}
+		}
+	}
+	return true
+}
+
+func (rules SourceRules) Apply(raffles []Raffle) []Raffle {
+	if len(rules) == 0 {
+		return raffles
+	}
+
+	var allowed []Raffle
+	for _, r := range raffles {
+		if rules.Allows(r.Post) {
+			allowed = append(allowed, r)
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,50 @@
+package models
+
+import "testing"
+
+func TestSourceRulesAllows(t *testing.T) {
+	const subscriber int64 = 42
+	post := Post{Author: DtfUserInfo{Id: 1}, Subsite: Subsite{Id: 2}}
+
+	tests := []struct {
+		name  string
+		rules SourceRules
+		want  bool
+	}{
+		{name: "no rules", want: true},
+		{
+			name:  "global deny of author",
+			rules: SourceRules{{Owner: GlobalRulesOwner, Kind: SourceAuthor, SourceId: 1, Mode: SourceDeny}},
+			want:  false,
+		},
+		{
+			name: "personal allow overrides global deny",
+			rules: SourceRules{
+				{Owner: GlobalRulesOwner, Kind: SourceAuthor, SourceId: 1, Mode: SourceDeny},
+				{Owner: subscriber, Kind: SourceAuthor, SourceId: 1, Mode: SourceAllow},
+			},
+			want: true,
+		},
+		{
+			name: "author allow in denied subsite",
+			rules: SourceRules{
+				{Owner: subscriber, Kind: SourceSubsite, SourceId: 2, Mode: SourceDeny},
+				{Owner: subscriber, Kind: SourceAuthor, SourceId: 1, Mode: SourceAllow},
+			},
+			want: true,
+		},
+		{
+			name:  "other author denied",
+			rules: SourceRules{{Owner: subscriber, Kind: SourceAuthor, SourceId: 3, Mode: SourceDeny}},
+			want:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rules.Allows(post); got != tt.want {
+				t.Errorf("Allows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
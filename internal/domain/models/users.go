@@ -2,17 +2,81 @@ package models
 
 import "time"
 
+type SessionStatus string
+
+const (
+	SessionActive       SessionStatus = "active"
+	SessionNeedsRelogin SessionStatus = "needs_relogin" // refresh token is dead
+)
+
 type DtfUserSession struct {
-	Email            string
-	AccessToken      string
-	RefreshToken     string
-	AccessExpiration time.Time
+	Email             string
+	AccessToken       string
+	RefreshToken      string
+	AccessExpiration  time.Time
+	RefreshExpiration time.Time // zero if unknown
+
+	Status          SessionStatus
+	LastRefreshedAt time.Time
+	LastError       string
+
+	TelegramId      int64 // linked telegram subscriber, 0 if none
+	AutoParticipate bool  // opted in to automatic raffle participation
+}
+
+func (s DtfUserSession) NeedsRelogin() bool {
+	return s.Status == SessionNeedsRelogin
+}
+
+// ExpiresWithin checks if access or refresh token dies in the nearest future
+func (s DtfUserSession) ExpiresWithin(d time.Duration) bool {
+	deadline := time.Now().Add(d)
+	if s.AccessExpiration.Before(deadline) {
+		return true
+	}
+	return !s.RefreshExpiration.IsZero() && s.RefreshExpiration.Before(deadline)
+}
+
+func (s DtfUserSession) Health() SessionHealth {
+	health := SessionHealth{
+		Email:           s.Email,
+		Status:          s.Status,
+		AccessValidFor:  time.Until(s.AccessExpiration),
+		LastRefreshedAt: s.LastRefreshedAt,
+		LastError:       s.LastError,
+	}
+	if !s.RefreshExpiration.IsZero() {
+		health.RefreshValidFor = time.Until(s.RefreshExpiration)
+	}
+	return health
+}
+
+// SessionHealth describes state of stored DTF session without tokens
+type SessionHealth struct {
+	Email           string
+	Status          SessionStatus
+	AccessValidFor  time.Duration // negative if expired
+	RefreshValidFor time.Duration // 0 if unknown
+	LastRefreshedAt time.Time
+	LastError       string
+}
+
+func (h SessionHealth) IsHealthy() bool {
+	return h.Status == SessionActive && h.AccessValidFor > 0
+}
+
+// SessionRefreshReport is a result of refreshing many sessions
+type SessionRefreshReport struct {
+	Refreshed    []string // emails
+	NeedsRelogin []string
+	Failed       []string
 }
 
 type DtfUserInfo struct {
-	Id   int
-	Name string
-	Url  string
+	Id        int
+	Name      string
+	Url       string
+	CreatedAt time.Time // zero if unknown
 }
 
 type TelegramSession struct {
@@ -0,0 +1,185 @@
+package raffle
+
+import (
+	"dtf/game_draw/internal/domain/models"
+	"fmt"
+	"math"
+	"regexp"
+)
+
+type signal int
+
+const (
+	signalRaffle signal = iota
+	signalResults
+)
+
+func (s signal) String() string {
+	if s == signalResults {
+		return "results"
+	}
+	return "raffle"
+}
+
+type rule struct {
+	pattern pattern
+	signal  signal
+	weight  int // negative weight is evidence against
+}
+
+func newRule(phrase string, s signal, weight int) rule {
+	return rule{pattern: newPattern(phrase), signal: s, weight: weight}
+}
+
+const (
+	// title is short and written on purpose, it weighs more than text
+	titleMultiplier = 3
+	// a single text mention of raffle is enough, weak words alone are not
+	minRaffleScore = 3
+	// long texts repeat the same words, repetition is not more evidence
+	maxTextMatches = 2
+)
+
+var classifierRules = []rule{
+	newRule("розыгрыш*", signalRaffle, 3),
+	newRule("разыгрыва*", signalRaffle, 3),
+	newRule("разыгра*", signalRaffle, 2),
+	newRule("раздач*", signalRaffle, 3),
+	newRule("раздаю", signalRaffle, 3),
+	newRule("раздаем", signalRaffle, 3),
+	newRule("giveaway*", signalRaffle, 3),
+	newRule("конкурс*", signalRaffle, 2),
+	newRule("дарим", signalRaffle, 2),
+	newRule("дарю", signalRaffle, 2),
+	newRule("ключ*", signalRaffle, 1),
+	newRule("для участия", signalRaffle, 2),
+	newRule("условия участия", signalRaffle, 2),
+	newRule("будет выбран*", signalRaffle, 1),
+	newRule("первоапрельск*", signalRaffle, -6),
+	newRule("пранк*", signalRaffle, -3),
+
+	newRule("итог*", signalResults, 5),
+	newRule("подвед* итог*", signalResults, 2),
+	newRule("результат* розыгрыш*", signalResults, 5),
+	newRule("результат* конкурс*", signalResults, 5),
+	newRule("результат*", signalResults, 1),
+	newRule("разыграли", signalResults, 4),
+	newRule("завершен*", signalResults, 4),
+	newRule("завершил*", signalResults, 4),
+	newRule("закончен*", signalResults, 4),
+	newRule("закончил*", signalResults, 4),
+	newRule("окончен*", signalResults, 4),
+	newRule("победител* розыгрыш*", signalResults, 4),
+	newRule("победител* конкурс*", signalResults, 4),
+	newRule("победител*", signalResults, 1),
+	newRule("поздравля*", signalResults, 2),
+	newRule("выиграл*", signalResults, 2),
+	newRule("подведены", signalResults, 3),
+	newRule("подведен", signalResults, 3),
+	newRule("подвели", signalResults, 3),
+	newRule("победил*", signalResults, 2),
+}
+
+// sentences about future results are deadlines of active raffle:
+// "итоги 1.11 в 18:00", "победителя выберу рандомом"
+var (
+	futurePatterns = []pattern{
+		newPattern("будет"), newPattern("будут"), newPattern("подведение"),
+		newPattern("подведу"), newPattern("подведем"), newPattern("подводиться"),
+		newPattern("выберу"), newPattern("выберем"), newPattern("определю"), newPattern("определим"),
+		newPattern("объявлю"), newPattern("объявим"), newPattern("опубликую"), newPattern("опубликуем"),
+	}
+	// past tense wins over dates: "итоги розыгрыша от 25 октября подведены"
+	pastPatterns = []pattern{
+		newPattern("подведены"), newPattern("подведен"), newPattern("подвели"), newPattern("разыграли"),
+		newPattern("выбраны"), newPattern("выбрали"), newPattern("определены"), newPattern("определили"),
+		newPattern("выиграл*"), newPattern("победил*"),
+	}
+	dateRes = []*regexp.Regexp{monthDateRe, numericDateRe, relativeRe, weekdayRe, dayWordRe}
+)
+
+// Classify scores post title and text with weighted rules.
+// Post without enough raffle evidence is not a raffle,
+// otherwise it is results post if results evidence outweighs raffle one.
+func Classify(post models.Post) models.RaffleClassification {
+	result := models.RaffleClassification{}
+
+	apply := func(where string, raffleWords, resultsWords []string, multiplier, maxMatches int) {
+		for _, r := range classifierRules {
+			found := 0
+			switch r.signal {
+			case signalRaffle:
+				found = r.pattern.count(raffleWords)
+			case signalResults:
+				found = r.pattern.count(resultsWords)
+			}
+			if found == 0 {
+				continue
+			}
+			found = min(found, maxMatches)
+
+			score := r.weight * found * multiplier
+			switch r.signal {
+			case signalRaffle:
+				result.RaffleScore += score
+			case signalResults:
+				result.ResultsScore += score
+			}
+			result.Reasons = append(
+				result.Reasons,
+				fmt.Sprintf("%s %q %+d %s", where, r.pattern, score, r.signal),
+			)
+		}
+	}
+	apply("title", words(post.Title), resultsWords(post.Title), titleMultiplier, math.MaxInt)
+	apply("text", words(post.Text), resultsWords(post.Text), 1, maxTextMatches)
+
+	switch {
+	case result.RaffleScore < minRaffleScore:
+		result.Kind = models.NotRaffle
+	case result.ResultsScore > result.RaffleScore:
+		result.Kind = models.RaffleResults
+	default:
+		result.Kind = models.RaffleActive
+	}
+
+	return result
+}
+
+// resultsWords returns words of sentences which may be about finished raffle,
+// sentences about future results are dropped
+func resultsWords(text string) []string {
+	var result []string
+	for _, sentence := range sentenceSplitRe.Split(text, -1) {
+		sentenceWords := words(sentence)
+		if isFutureResults(sentence, sentenceWords) {
+			continue
+		}
+		result = append(result, sentenceWords...)
+	}
+	return result
+}
+
+func isFutureResults(sentence string, sentenceWords []string) bool {
+	if matchAny(futurePatterns, sentenceWords) {
+		return true
+	}
+	if matchAny(pastPatterns, sentenceWords) {
+		return false
+	}
+
+	normalized := normalize(sentence)
+	for _, re := range dateRes {
+		for _, m := range re.FindAllStringSubmatchIndex(normalized, -1) {
+			start := m[0]
+			if m[2] >= 0 {
+				start = m[2]
+			}
+			// "итоги розыгрыша от 25.10" is the date of raffle, not of results
+			if !startMarkerRe.MatchString(normalized[:start]) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package raffle
+
+import (
+	"dtf/game_draw/internal/domain/models"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		text  string
+		want  models.RaffleKind
+	}{
+		{
+			name:  "raffle with results date in text",
+			title: "Розыгрыш Cyberpunk 2077",
+			text: "Итоги 1.11 в 18:00 МСК. Для участия лайк и коммент. " +
+				"Победителя выберу рандомом. Подведение итогов в пятницу.",
+			want: models.RaffleActive,
+		},
+		{
+			name:  "raffle with results date in title",
+			title: "Розыгрыш ключа Hades II, итоги 25 октября",
+			text:  "Для участия поставьте лайк.",
+			want:  models.RaffleActive,
+		},
+		{
+			name:  "raffle with results weekday in title",
+			title: "Раздача ключей: итоги в пятницу",
+			text:  "Лайк и коммент.",
+			want:  models.RaffleActive,
+		},
+		{
+			name:  "raffle with future results",
+			title: "Раздаю ключи Steam",
+			text:  "Условия участия: лайк. Итоги будут завтра, победителей объявлю в комментариях.",
+			want:  models.RaffleActive,
+		},
+		{
+			name:  "raffle repeating results word",
+			title: "Конкурс на Hades 2",
+			text:  "Для участия напишите коммент. Итоги, итоги, итоги через неделю.",
+			want:  models.RaffleActive,
+		},
+		{
+			name:  "results title",
+			title: "Итоги розыгрыша Cyberpunk 2077",
+			text:  "Всем спасибо за участие! Победители: @alex и @kate, ключи отправлены в личку.",
+			want:  models.RaffleResults,
+		},
+		{
+			name:  "results title with date",
+			title: "Итоги розыгрыша от 25.10",
+			text:  "Поздравляю победителей, ключи отправлены.",
+			want:  models.RaffleResults,
+		},
+		{
+			name:  "results title with month date",
+			title: "Итоги розыгрыша от 25 октября",
+			text:  "Поздравляю победителей.",
+			want:  models.RaffleResults,
+		},
+		{
+			name:  "results in past tense text",
+			title: "Спасибо за участие",
+			text:  "Итоги розыгрыша от 25 октября подведены. Победители розыгрыша: @alex и @kate.",
+			want:  models.RaffleResults,
+		},
+		{
+			name:  "raffle ended",
+			title: "Розыгрыш завершен",
+			text:  "Разыграли три ключа, спасибо всем.",
+			want:  models.RaffleResults,
+		},
+		{
+			name:  "not a raffle",
+			title: "Обзор Cyberpunk 2077",
+			text:  "Итоги года и лучшие игры.",
+			want:  models.NotRaffle,
+		},
+		{
+			name:  "april fools raffle",
+			title: "Первоапрельский розыгрыш",
+			text:  "Никаких ключей.",
+			want:  models.NotRaffle,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Classify(models.Post{Title: tt.title, Text: tt.text})
+			if got.Kind != tt.want {
+				t.Errorf("Classify() kind = %v, want %v, reasons: %v", got.Kind, tt.want, got.Reasons)
+			}
+		})
+	}
+}
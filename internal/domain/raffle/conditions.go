@@ -0,0 +1,230 @@
+package raffle
+
+import (
+	"dtf/game_draw/internal/domain/models"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	sentenceSplitRe = regexp.MustCompile(`[!?\n;]+|\.(?:\s|$)`)
+	urlRe           = regexp.MustCompile(`(?:https?://|\b(?:t\.me|discord\.gg)/)[^\s<>"'«»()]+`)
+
+	// comment text is usually quoted: напишите в комментариях «Участвую»
+	commentQuotedRe = regexp.MustCompile(`(?i)(?:коммент\p{L}*|напи\p{L}*|comment\p{L}*)[^"«“„\n]{0,40}["«“„]([^"»”“\n]{1,80})["»”“]`)
+	// or follows a word explicitly: комментарий со словом Участвую, фраза: Участвую.
+	// "в двух словах о игре" or "с любой фразой про игру" don't set the text.
+	commentWordRe = regexp.MustCompile(`(?i)(?:(?:слово|словом|фраза|фразой)\s*[:\-–—]|со\s+словом)\s*([\p{L}\d]+)`)
+
+	accountAgeRe = regexp.MustCompile(
+		`аккаунт\p{L}*[^.!?\n]{0,40}?(?:младше|моложе|от|старше|более|больше|минимум)\s+(\d+)?\s*(дн|день|недел|месяц|мес|год|лет)`,
+	)
+	// number right next to karma: карма от 10, карма: 10+, 10 кармы
+	karmaRe = regexp.MustCompile(
+		`карм\p{L}*\s*(?:(?:от|не ниже|не меньше|не менее|больше|выше|более|минимум)\s*)?[:>=≥\-–—]*\s*(\d+)|(\d+)\+?\s*карм`,
+	)
+)
+
+var (
+	likePatterns = []pattern{
+		newPattern("лайк*"), newPattern("лайкн*"), newPattern("like*"),
+		newPattern("оцен* пост*"), newPattern("плюсан*"), newPattern("плюс* посту"),
+	}
+	commentPatterns = []pattern{
+		newPattern("коммент*"), newPattern("comment*"), newPattern("напиш* под пост*"),
+	}
+	repostPatterns = []pattern{
+		newPattern("репост*"), newPattern("repost*"), newPattern("поделит* пост*"),
+	}
+	subscribePatterns = []pattern{
+		// not "подпис*", it matches "для подписчиков"
+		newPattern("подписа*"), newPattern("подписк*"), newPattern("подписыв*"), newPattern("подпиш*"),
+		newPattern("subscribe*"), newPattern("follow*"),
+	}
+	// actions which make sense only outside of DTF
+	externalPatterns = []pattern{
+		newPattern("вступ*"), newPattern("присоедин*"), newPattern("join*"),
+		newPattern("желаем*"), newPattern("вишлист*"), newPattern("wishlist*"),
+	}
+	// condition is not required
+	optionalPatterns = []pattern{
+		newPattern("не обязател*"), newPattern("необязател*"), newPattern("по желанию"),
+	}
+	// karma is mentioned, but doesn't matter
+	anyKarmaPatterns = []pattern{
+		newPattern("не важн*"), newPattern("неважн*"), newPattern("не нужн*"), newPattern("не треб*"),
+		newPattern("не имеет значения"), newPattern("любая карм*"), newPattern("любой карм*"),
+		newPattern("без карм*"),
+	}
+)
+
+var servicePatterns = map[models.ExternalService][]pattern{
+	models.ServiceSteam:    {newPattern("steam"), newPattern("стим*")},
+	models.ServiceTelegram: {newPattern("telegram*"), newPattern("телеграм*"), newPattern("тг"), newPattern("tg")},
+	models.ServiceDiscord:  {newPattern("discord*"), newPattern("дискорд*")},
+	models.ServiceVk:       {newPattern("vk"), newPattern("вк"), newPattern("вконтакте")},
+	models.ServiceYoutube:  {newPattern("youtube"), newPattern("ютуб*")},
+	models.ServiceTwitch:   {newPattern("twitch"), newPattern("твич*")},
+}
+
+var serviceHosts = map[string]models.ExternalService{
+	"steampowered.com":   models.ServiceSteam,
+	"steamcommunity.com": models.ServiceSteam,
+	"t.me":               models.ServiceTelegram,
+	"telegram.me":        models.ServiceTelegram,
+	"discord.gg":         models.ServiceDiscord,
+	"discord.com":        models.ServiceDiscord,
+	"vk.com":             models.ServiceVk,
+	"youtube.com":        models.ServiceYoutube,
+	"youtu.be":           models.ServiceYoutube,
+	"twitch.tv":          models.ServiceTwitch,
+}
+
+// ExtractConditions finds participation conditions in post title and text.
+// Text is split into sentences, lists are already one item per line.
+// Sentences marked as optional ("по желанию") are ignored.
+func ExtractConditions(post models.Post) models.RaffleConditions {
+	var conditions models.RaffleConditions
+	text := post.Title + "\n" + post.Text
+
+	for _, sentence := range sentenceSplitRe.Split(text, -1) {
+		sentenceWords := words(sentence)
+		if len(sentenceWords) == 0 || matchAny(optionalPatterns, sentenceWords) {
+			continue
+		}
+
+		if matchAny(likePatterns, sentenceWords) {
+			conditions.Like = true
+		}
+		if matchAny(commentPatterns, sentenceWords) {
+			conditions.Comment = true
+			if conditions.CommentText == "" {
+				conditions.CommentText = commentText(sentence)
+			}
+		}
+		if matchAny(repostPatterns, sentenceWords) {
+			conditions.Repost = true
+		}
+		if conditions.MinKarma == 0 && !matchAny(anyKarmaPatterns, sentenceWords) {
+			conditions.MinKarma = minKarma(sentence)
+		}
+
+		subscribe := matchAny(subscribePatterns, sentenceWords)
+		if !subscribe && !matchAny(externalPatterns, sentenceWords) {
+			continue
+		}
+		external := externalActions(sentence, sentenceWords)
+		for _, action := range external {
+			if !slices.Contains(conditions.External, action) {
+				conditions.External = append(conditions.External, action)
+			}
+		}
+		if subscribe && len(external) == 0 {
+			conditions.SubscribeAuthor = true
+		}
+	}
+
+	normalized := normalize(text)
+	if m := accountAgeRe.FindStringSubmatch(normalized); m != nil {
+		conditions.MinAccountDays = toDays(m[1], m[2])
+	}
+
+	return conditions
+}
+
+func matchAny(patterns []pattern, words []string) bool {
+	for _, p := range patterns {
+		if p.count(words) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func minKarma(sentence string) int {
+	m := karmaRe.FindStringSubmatch(normalize(sentence))
+	if m == nil {
+		return 0
+	}
+	karma, _ := strconv.Atoi(m[1] + m[2])
+	return karma
+}
+
+func commentText(sentence string) string {
+	if m := commentQuotedRe.FindStringSubmatch(sentence); m != nil {
+		return strings.TrimSpace(m[1])
+	}
+	if m := commentWordRe.FindStringSubmatch(sentence); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// externalActions returns links to other services in the sentence,
+// if there are none, mentioned services without links
+func externalActions(sentence string, sentenceWords []string) []models.ExternalAction {
+	var actions []models.ExternalAction
+	for _, link := range urlRe.FindAllString(sentence, -1) {
+		service, ok := linkService(link)
+		if !ok {
+			continue
+		}
+		actions = append(actions, models.ExternalAction{Service: service, Url: link})
+	}
+	if len(actions) > 0 {
+		return actions
+	}
+
+	for service, patterns := range servicePatterns {
+		if matchAny(patterns, sentenceWords) {
+			actions = append(actions, models.ExternalAction{Service: service})
+		}
+	}
+	// map order is random
+	slices.SortFunc(actions, func(a, b models.ExternalAction) int {
+		return strings.Compare(string(a.Service), string(b.Service))
+	})
+	return actions
+}
+
+// linkService detects service by link host, DTF links are not external
+func linkService(link string) (models.ExternalService, bool) {
+	if !strings.Contains(link, "://") {
+		link = "https://" + link
+	}
+	u, err := url.Parse(link)
+	if err != nil || u.Hostname() == "" {
+		return "", false
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if host == "dtf.ru" || strings.HasSuffix(host, ".dtf.ru") {
+		return "", false
+	}
+	for domain, service := range serviceHosts {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return service, true
+		}
+	}
+	return models.ServiceOther, true
+}
+
+func toDays(count, unit string) int {
+	n := 1
+	if count != "" {
+		n, _ = strconv.Atoi(count)
+	}
+	switch {
+	case strings.HasPrefix(unit, "недел"):
+		return n * 7
+	case strings.HasPrefix(unit, "мес"):
+		return n * 30
+	case unit == "год" || unit == "лет":
+		return n * 365
+	default:
+		return n
+	}
+}
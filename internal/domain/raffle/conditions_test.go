@@ -0,0 +1,91 @@
+package raffle
+
+import (
+	"dtf/game_draw/internal/domain/models"
+	"testing"
+)
+
+func TestExtractConditions(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want models.RaffleConditions
+	}{
+		{
+			name: "quoted comment text",
+			text: "Для участия поставьте лайк и напишите в комментариях «Участвую».",
+			want: models.RaffleConditions{Like: true, Comment: true, CommentText: "Участвую"},
+		},
+		{
+			name: "comment with explicit word",
+			text: "Оставьте комментарий со словом Хочу",
+			want: models.RaffleConditions{Comment: true, CommentText: "Хочу"},
+		},
+		{
+			name: "comment with word after colon",
+			text: "Напишите комментарий, фраза: Ведьмак",
+			want: models.RaffleConditions{Comment: true, CommentText: "Ведьмак"},
+		},
+		{
+			name: "comment in a few words is not a text",
+			text: "Напишите в комментарии в двух словах о любимой игре",
+			want: models.RaffleConditions{Comment: true},
+		},
+		{
+			name: "comment with any phrase is not a text",
+			text: "Оставьте комментарий с любой фразой про Ведьмака",
+			want: models.RaffleConditions{Comment: true},
+		},
+		{
+			name: "karma after word",
+			text: "Карма от 10. Лайк посту",
+			want: models.RaffleConditions{Like: true, MinKarma: 10},
+		},
+		{
+			name: "karma before word",
+			text: "Нужно 50 кармы",
+			want: models.RaffleConditions{MinKarma: 50},
+		},
+		{
+			name: "karma doesn't matter",
+			text: "Карма не важна, итоги 25 октября",
+			want: models.RaffleConditions{},
+		},
+		{
+			name: "karma is not next to number",
+			text: "Карма любая, розыгрыш до 25 октября",
+			want: models.RaffleConditions{},
+		},
+		{
+			name: "subscribe to author",
+			text: "Подпишитесь на автора и поставьте лайк",
+			want: models.RaffleConditions{Like: true, SubscribeAuthor: true},
+		},
+		{
+			name: "raffle for subscribers is not a condition",
+			text: "Розыгрыш для подписчиков блога",
+			want: models.RaffleConditions{},
+		},
+		{
+			name: "optional condition is ignored",
+			text: "Репост по желанию",
+			want: models.RaffleConditions{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExtractConditions(models.Post{Text: tt.text})
+			if got.Like != tt.want.Like ||
+				got.Comment != tt.want.Comment ||
+				got.CommentText != tt.want.CommentText ||
+				got.SubscribeAuthor != tt.want.SubscribeAuthor ||
+				got.Repost != tt.want.Repost ||
+				len(got.External) != len(tt.want.External) ||
+				got.MinAccountDays != tt.want.MinAccountDays ||
+				got.MinKarma != tt.want.MinKarma {
+				t.Errorf("ExtractConditions(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package raffle
+
+import (
+	"dtf/game_draw/internal/domain/models"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Moscow has no DST since 2014, fixed zone doesn't need tzdata
+var moscow = time.FixedZone("MSK", 3*60*60)
+
+// how far before date we look for end markers, in runes
+const markerWindow = 30
+
+var (
+	monthDateRe = regexp.MustCompile(
+		`(?:^|[^\p{L}\d])(\d{1,2})\s+(январ|феврал|март|апрел|ма[йя]|июн|июл|август|сентябр|октябр|ноябр|декабр)\p{L}*(?:\s+(\d{4}))?`,
+	)
+	numericDateRe = regexp.MustCompile(`(?:^|[^\d.])(\d{1,2})\.(\d{1,2})(?:\.(\d{4}|\d{2}))?(?:[^\d.]|$)`)
+	relativeRe    = regexp.MustCompile(
+		`(?:^|[^\p{L}])через\s+(?:(\d+|одн\p{L}*|два|две|три|четыре|пять|шесть|семь|десять)\s+)?(час|день|дн|сут|недел|месяц)\p{L}*`,
+	)
+	weekdayRe = regexp.MustCompile(
+		`(?:^|[^\p{L}])(?:в|во|до|к)\s+(понедельник|вторник|сред|четверг|пятниц|суббот|воскресен)\p{L}*`,
+	)
+	dayWordRe = regexp.MustCompile(`(?:^|[^\p{L}])(послезавтра|завтра|сегодня)(?:[^\p{L}]|$)`)
+	// time right after the date: "25 октября в 18:00", "1.11, 18.00 мск"
+	timeRe = regexp.MustCompile(`^[\s,]*(?:года\s+)?(?:в|до|к|около)?\s*(\d{1,2})[:.](\d{2})(?:[^\d]|$)`)
+	// start markers mean the date is not a deadline: "с 20 октября"
+	startMarkerRe = regexp.MustCompile(`(?:^|[^\p{L}])(?:с|от|начиная|стартует|старт)\s*$`)
+	endMarkerRe   = regexp.MustCompile(
+		`(?:^|[^\p{L}])(?:до|по|итог|результат|заверш|законч|заканчива|оконча|подвед|подвод|дедлайн|победител|выбер|определ|продлится)`,
+	)
+)
+
+var monthStems = map[string]time.Month{
+	"январ":   time.January,
+	"феврал":  time.February,
+	"март":    time.March,
+	"апрел":   time.April,
+	"май":     time.May,
+	"мая":     time.May,
+	"июн":     time.June,
+	"июл":     time.July,
+	"август":  time.August,
+	"сентябр": time.September,
+	"октябр":  time.October,
+	"ноябр":   time.November,
+	"декабр":  time.December,
+}
+
+var weekdayStems = map[string]time.Weekday{
+	"понедельник": time.Monday,
+	"вторник":     time.Tuesday,
+	"сред":        time.Wednesday,
+	"четверг":     time.Thursday,
+	"пятниц":      time.Friday,
+	"суббот":      time.Saturday,
+	"воскресен":   time.Sunday,
+}
+
+var numberWords = map[string]int{
+	"два":    2,
+	"две":    2,
+	"три":    3,
+	"четыре": 4,
+	"пять":   5,
+	"шесть":  6,
+	"семь":   7,
+	"десять": 10,
+}
+
+// ExtractDeadline finds raffle end in post title and text.
+// Dates are resolved relative to post publication date in Moscow time,
+// dates without time mean the end of that day.
+// If several dates found, the most confident and then the latest one wins.
+func ExtractDeadline(post models.Post) models.Deadline {
+	published := post.PublishedAt
+	if published.IsZero() {
+		published = time.Now()
+	}
+	published = published.In(moscow)
+
+	text := normalize(post.Title + "\n" + post.Text)
+
+	var candidates []models.Deadline
+	candidates = append(candidates, absoluteDates(text, published)...)
+	candidates = append(candidates, relativeDates(text, published)...)
+
+	var best models.Deadline
+	for _, c := range candidates {
+		if c.Confidence > best.Confidence ||
+			(c.Confidence == best.Confidence && c.Time.After(best.Time)) {
+			best = c
+		}
+	}
+
+	return best
+}
+
+func absoluteDates(text string, published time.Time) []models.Deadline {
+	var result []models.Deadline
+	midnight := time.Date(published.Year(), published.Month(), published.Day(), 0, 0, 0, 0, moscow)
+
+	parse := func(re *regexp.Regexp, month func(string) (time.Month, bool)) {
+		for _, m := range re.FindAllStringSubmatchIndex(text, -1) {
+			day, _ := strconv.Atoi(text[m[2]:m[3]])
+			mon, ok := month(text[m[4]:m[5]])
+			if !ok || day < 1 || day > 31 {
+				continue
+			}
+			year, explicitYear := 0, m[6] >= 0
+			if explicitYear {
+				year, _ = strconv.Atoi(text[m[6]:m[7]])
+				if year < 100 {
+					year += 2000
+				}
+			}
+
+			c, ok := newCandidate(text, m[2], m[1], func(hour, minute int) time.Time {
+				y := year
+				if !explicitYear {
+					y = published.Year()
+				}
+				return time.Date(y, mon, day, hour, minute, 0, 0, moscow)
+			})
+			if !ok {
+				continue
+			}
+			// day overflow like 31.02 is normalized by time.Date, skipping it
+			if c.Time.Day() != day {
+				continue
+			}
+
+			if c.Time.Before(midnight) {
+				// "до 5 января" in december is next year,
+				// past dates without marker are just mentions
+				if explicitYear || c.Confidence < models.DeadlineMedium {
+					continue
+				}
+				c.Time = c.Time.AddDate(1, 0, 0)
+			}
+			result = append(result, c)
+		}
+	}
+
+	parse(monthDateRe, func(s string) (time.Month, bool) {
+		for stem, month := range monthStems {
+			if strings.HasPrefix(s, stem) {
+				return month, true
+			}
+		}
+		return 0, false
+	})
+	parse(numericDateRe, func(s string) (time.Month, bool) {
+		month, err := strconv.Atoi(s)
+		if err != nil || month < 1 || month > 12 {
+			return 0, false
+		}
+		return time.Month(month), true
+	})
+
+	return result
+}
+
+func relativeDates(text string, published time.Time) []models.Deadline {
+	var result []models.Deadline
+	midnight := time.Date(published.Year(), published.Month(), published.Day(), 0, 0, 0, 0, moscow)
+
+	for _, m := range relativeRe.FindAllStringSubmatchIndex(text, -1) {
+		count := 1
+		if m[2] >= 0 {
+			countStr := text[m[2]:m[3]]
+			if n, err := strconv.Atoi(countStr); err == nil {
+				count = n
+			} else if n, ok := numberWords[countStr]; ok {
+				count = n
+			}
+		}
+		unit := text[m[4]:m[5]]
+
+		c, ok := newCandidate(text, m[0], m[1], func(hour, minute int) time.Time {
+			var day time.Time
+			switch unit {
+			case "час":
+				return published.Add(time.Duration(count) * time.Hour)
+			case "недел":
+				day = midnight.AddDate(0, 0, 7*count)
+			case "месяц":
+				day = midnight.AddDate(0, count, 0)
+			default:
+				day = midnight.AddDate(0, 0, count)
+			}
+			return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+		})
+		if !ok {
+			continue
+		}
+		if unit == "час" {
+			c.DateOnly = false
+		}
+		result = append(result, lowered(c))
+	}
+
+	for _, m := range weekdayRe.FindAllStringSubmatchIndex(text, -1) {
+		weekday := weekdayStems[text[m[2]:m[3]]]
+		days := (int(weekday) - int(published.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+
+		c, ok := newCandidate(text, m[2], m[1], func(hour, minute int) time.Time {
+			return midnight.AddDate(0, 0, days).Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+		})
+		if ok {
+			result = append(result, lowered(c))
+		}
+	}
+
+	for _, m := range dayWordRe.FindAllStringSubmatchIndex(text, -1) {
+		days := 0
+		switch text[m[2]:m[3]] {
+		case "завтра":
+			days = 1
+		case "послезавтра":
+			days = 2
+		}
+
+		c, ok := newCandidate(text, m[2], m[3], func(hour, minute int) time.Time {
+			return midnight.AddDate(0, 0, days).Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+		})
+		if ok {
+			result = append(result, lowered(c))
+		}
+	}
+
+	return result
+}
+
+// newCandidate checks context around the date found at text[start:end]
+// and builds deadline with time mentioned right after it
+func newCandidate(
+	text string,
+	start, end int,
+	at func(hour, minute int) time.Time,
+) (models.Deadline, bool) {
+	before := []rune(text[:start])
+	if len(before) > markerWindow {
+		before = before[len(before)-markerWindow:]
+	}
+	if startMarkerRe.MatchString(string(before)) {
+		return models.Deadline{}, false
+	}
+	hasMarker := endMarkerRe.MatchString(string(before))
+
+	hour, minute, hasTime := 23, 59, false
+	if m := timeRe.FindStringSubmatchIndex(text[end:]); m != nil {
+		h, _ := strconv.Atoi(text[end+m[2] : end+m[3]])
+		mm, _ := strconv.Atoi(text[end+m[4] : end+m[5]])
+		if h < 24 && mm < 60 {
+			hour, minute, hasTime = h, mm, true
+			end += m[5]
+		}
+	}
+
+	confidence := models.DeadlineLow
+	switch {
+	case hasMarker && hasTime:
+		confidence = models.DeadlineHigh
+	case hasMarker || hasTime:
+		confidence = models.DeadlineMedium
+	}
+
+	return models.Deadline{
+		Time:       at(hour, minute),
+		Confidence: confidence,
+		DateOnly:   !hasTime,
+		Source: strings.TrimFunc(text[start:end], func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}),
+	}, true
+}
+
+// lowered decreases confidence of relative dates,
+// they are often about something else than the raffle end
+func lowered(c models.Deadline) models.Deadline {
+	if c.Confidence > models.DeadlineLow {
+		c.Confidence--
+	}
+	return c
+}
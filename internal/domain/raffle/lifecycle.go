@@ -0,0 +1,44 @@
+package raffle
+
+import (
+	"dtf/game_draw/internal/domain/models"
+	"time"
+)
+
+const (
+	// EndingSoonWindow is how long before deadline raffle is ending soon
+	EndingSoonWindow = 24 * time.Hour
+	// raffles without deadline are considered ended after that
+	maxRaffleAge = 14 * 24 * time.Hour
+)
+
+// NextState moves stored raffle through its lifecycle:
+// new -> active -> ending soon -> ended -> abandoned.
+// Ended raffle becomes active again if organizer moved the deadline.
+// Results published is set when results post is found and is never left.
+// Abandoned raffle is not waited for results anymore and is never left too.
+func NextState(raffle models.Raffle, now time.Time) models.RaffleState {
+	if raffle.State == models.RaffleStateResultsPublished || raffle.State == models.RaffleStateAbandoned {
+		return raffle.State
+	}
+
+	started := raffle.PublishedAt
+	if started.IsZero() {
+		started = raffle.FirstSeenAt
+	}
+	ended := started.Add(maxRaffleAge)
+	if raffle.EndsAt.IsKnown() {
+		ended = raffle.EndsAt.Time
+	}
+
+	switch {
+	case now.Sub(ended) > resultsGracePeriod:
+		return models.RaffleStateAbandoned
+	case !now.Before(ended):
+		return models.RaffleStateEnded
+	case raffle.EndsAt.IsKnown() && ended.Sub(now) <= EndingSoonWindow:
+		return models.RaffleStateEndingSoon
+	default:
+		return models.RaffleStateActive
+	}
+}
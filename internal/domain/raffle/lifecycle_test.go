@@ -0,0 +1,78 @@
+package raffle
+
+import (
+	"dtf/game_draw/internal/domain/models"
+	"testing"
+	"time"
+)
+
+func TestNextState(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	deadline := func(at time.Time) models.Deadline {
+		return models.Deadline{Time: at, Confidence: models.DeadlineHigh}
+	}
+	raffle := func(state models.RaffleState, published time.Time, endsAt models.Deadline) models.Raffle {
+		return models.Raffle{Post: models.Post{PublishedAt: published}, State: state, EndsAt: endsAt}
+	}
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name   string
+		raffle models.Raffle
+		want   models.RaffleState
+	}{
+		{
+			name:   "active",
+			raffle: raffle(models.RaffleStateNew, now.Add(-day), deadline(now.Add(3*day))),
+			want:   models.RaffleStateActive,
+		},
+		{
+			name:   "ending soon",
+			raffle: raffle(models.RaffleStateActive, now.Add(-day), deadline(now.Add(time.Hour))),
+			want:   models.RaffleStateEndingSoon,
+		},
+		{
+			name:   "ended",
+			raffle: raffle(models.RaffleStateEndingSoon, now.Add(-3*day), deadline(now.Add(-time.Hour))),
+			want:   models.RaffleStateEnded,
+		},
+		{
+			name:   "deadline moved",
+			raffle: raffle(models.RaffleStateEnded, now.Add(-3*day), deadline(now.Add(2*day))),
+			want:   models.RaffleStateActive,
+		},
+		{
+			name:   "no results long after deadline",
+			raffle: raffle(models.RaffleStateEnded, now.Add(-20*day), deadline(now.Add(-8*day))),
+			want:   models.RaffleStateAbandoned,
+		},
+		{
+			name:   "no deadline and no results",
+			raffle: raffle(models.RaffleStateEnded, now.Add(-22*day), models.Deadline{}),
+			want:   models.RaffleStateAbandoned,
+		},
+		{
+			name:   "no deadline, waiting for results",
+			raffle: raffle(models.RaffleStateEnded, now.Add(-16*day), models.Deadline{}),
+			want:   models.RaffleStateEnded,
+		},
+		{
+			name:   "abandoned stays abandoned",
+			raffle: raffle(models.RaffleStateAbandoned, now.Add(-20*day), deadline(now.Add(2*day))),
+			want:   models.RaffleStateAbandoned,
+		},
+		{
+			name:   "results published stays",
+			raffle: raffle(models.RaffleStateResultsPublished, now.Add(-30*day), deadline(now.Add(-20*day))),
+			want:   models.RaffleStateResultsPublished,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NextState(tt.raffle, now); got != tt.want {
+				t.Errorf("NextState() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
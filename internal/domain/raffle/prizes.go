@@ -0,0 +1,195 @@
+package raffle
+
+import (
+	"dtf/game_draw/internal/domain/models"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var prizeKindPatterns = []struct {
+	kind     models.PrizeKind
+	patterns []pattern
+}{
+	{models.PrizeKey, []pattern{
+		newPattern("ключ*"), newPattern("key"), newPattern("keys"),
+		newPattern("код* активации"), newPattern("промокод*"),
+	}},
+	{models.PrizeGiftCard, []pattern{
+		newPattern("подарочн* карт*"), newPattern("gift card*"), newPattern("giftcard*"),
+		newPattern("сертификат*"), newPattern("пополнени*"), newPattern("wallet"),
+	}},
+	{models.PrizeSubscription, []pattern{
+		newPattern("game pass"), newPattern("gamepass"), newPattern("ps plus"),
+		newPattern("ea play"), newPattern("nintendo switch online"),
+	}},
+	{models.PrizeMerch, []pattern{
+		newPattern("мерч*"), newPattern("футболк*"), newPattern("кружк*"), newPattern("худи"),
+		newPattern("толстовк*"), newPattern("фигурк*"), newPattern("постер*"), newPattern("стикер*"),
+	}},
+	{models.PrizeHardware, []pattern{
+		newPattern("видеокарт*"), newPattern("геймпад*"), newPattern("джойстик*"),
+		newPattern("клавиатур*"), newPattern("мышк*"), newPattern("наушник*"),
+		newPattern("монитор*"), newPattern("приставк*"), newPattern("steam deck"),
+		newPattern("ssd"), newPattern("rtx"),
+	}},
+	{models.PrizeGame, []pattern{
+		newPattern("копи*"), newPattern("гифт*"), newPattern("gift"),
+	}},
+}
+
+var platformPatterns = []struct {
+	platform models.Platform
+	patterns []pattern
+}{
+	{models.PlatformPC, []pattern{
+		newPattern("steam"), newPattern("стим*"), newPattern("pc"), newPattern("пк"),
+		newPattern("epic"), newPattern("egs"), newPattern("gog"), newPattern("ea app"),
+		newPattern("ubisoft connect"), newPattern("battle net"),
+	}},
+	{models.PlatformPlayStation, []pattern{
+		newPattern("ps4"), newPattern("ps5"), newPattern("psn"), newPattern("ps plus"),
+		newPattern("playstation*"), newPattern("плейстейшн*"), newPattern("пс4"), newPattern("пс5"),
+	}},
+	{models.PlatformXbox, []pattern{
+		newPattern("xbox*"), newPattern("иксбокс*"),
+	}},
+	{models.PlatformNintendo, []pattern{
+		newPattern("nintendo*"), newPattern("нинтендо"), newPattern("nintendo switch"),
+		newPattern("свитч*"), newPattern("eshop"),
+	}},
+	{models.PlatformMobile, []pattern{
+		newPattern("android"), newPattern("андроид*"), newPattern("ios"),
+		newPattern("app store"), newPattern("google play"),
+	}},
+}
+
+var (
+	// names after prize words: "ключ Steam от Hades", "3 копии игры Dead Cells"
+	gameAfterPrizeRe = regexp.MustCompile(
+		`(?:[Кк]люч|[Кк]опи|[Ии]гр|[Gg]ift)\p{L}*\s+(?:(?:Steam|GOG|EGS|PSN|PS4|PS5|Xbox|Switch)\s+)?` +
+			`(?:(?:от|на|к|для|в)\s+)?(?:игр\p{L}*\s+)?[«"“]?` +
+			`([A-Z0-9][\p{L}\d':&.\-]*(?:\s+(?:[A-Z0-9][\p{L}\d':&.\-]*|of|the|and|in|to|a|:)){0,6})`,
+	)
+	// quoted latin names: «Hades», "Hollow Knight"
+	quotedGameRe = regexp.MustCompile(`[«"“]([A-Z0-9][^»"”\n]{1,50})[»"”]`)
+)
+
+var winnerPatterns = []pattern{
+	newPattern("победител*"), newPattern("счастливчик*"), newPattern("человек*"),
+	newPattern("призов*"), newPattern("место"), newPattern("места"), newPattern("мест"),
+	newPattern("winner*"),
+}
+
+var prizeCountPatterns = []pattern{
+	newPattern("ключ*"), newPattern("копи*"), newPattern("код"), newPattern("кода"),
+	newPattern("кодов"), newPattern("keys"),
+}
+
+var countWords = map[string]int{
+	"один":   1,
+	"одна":   1,
+	"одного": 1,
+	"двух":   2,
+	"трех":   3,
+	"пяти":   5,
+	"десяти": 10,
+}
+
+// ExtractPrizes finds prize kinds, game names, platforms and winners count
+// in post title and text.
+func ExtractPrizes(post models.Post) models.RafflePrizes {
+	var prizes models.RafflePrizes
+	text := post.Title + "\n" + post.Text
+	textWords := words(text)
+
+	for _, kind := range prizeKindPatterns {
+		if matchAny(kind.patterns, textWords) {
+			prizes.Kinds = append(prizes.Kinds, kind.kind)
+		}
+	}
+	for _, platform := range platformPatterns {
+		if matchAny(platform.patterns, textWords) {
+			prizes.Platforms = append(prizes.Platforms, platform.platform)
+		}
+	}
+
+	prizes.Games = gameNames(text)
+	if len(prizes.Games) > 0 && !slices.Contains(prizes.Kinds, models.PrizeGame) {
+		prizes.Kinds = append(prizes.Kinds, models.PrizeGame)
+	}
+	prizes.Winners = winnersCount(textWords)
+
+	return prizes
+}
+
+func gameNames(text string) []string {
+	var names []string
+	add := func(name string) {
+		name = strings.Trim(name, " .,:-")
+		if name == "" || isPlatformName(name) {
+			return
+		}
+		if slices.ContainsFunc(names, func(n string) bool { return strings.EqualFold(n, name) }) {
+			return
+		}
+		names = append(names, name)
+	}
+
+	for _, m := range gameAfterPrizeRe.FindAllStringSubmatch(text, -1) {
+		add(m[1])
+	}
+	for _, m := range quotedGameRe.FindAllStringSubmatch(text, -1) {
+		add(m[1])
+	}
+
+	return names
+}
+
+// isPlatformName filters out "ключ Steam" like matches
+func isPlatformName(name string) bool {
+	nameWords := words(name)
+	for _, platform := range platformPatterns {
+		for _, p := range platform.patterns {
+			if len(p) == len(nameWords) && p.count(nameWords) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// winnersCount looks for "3 победителя", if there is none, for "5 ключей"
+func winnersCount(textWords []string) int {
+	winners, keys := 0, 0
+	for i := 0; i+1 < len(textWords); i++ {
+		n, ok := toNumber(textWords[i])
+		if !ok || n <= 0 || n > 1000 {
+			continue
+		}
+		next := textWords[i+1 : i+2]
+		switch {
+		case matchAny(winnerPatterns, next):
+			winners = max(winners, n)
+		case matchAny(prizeCountPatterns, next):
+			keys = max(keys, n)
+		}
+	}
+
+	if winners > 0 {
+		return winners
+	}
+	return keys
+}
+
+func toNumber(word string) (int, bool) {
+	if n, err := strconv.Atoi(word); err == nil {
+		return n, true
+	}
+	if n, ok := numberWords[word]; ok {
+		return n, true
+	}
+	n, ok := countWords[word]
+	return n, ok
+}
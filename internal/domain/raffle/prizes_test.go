@@ -0,0 +1,40 @@
+package raffle
+
+import (
+	"dtf/game_draw/internal/domain/models"
+	"slices"
+	"testing"
+)
+
+func TestExtractPrizesIgnoresCommonWords(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		wantKey      bool
+		wantNintendo bool
+		wantWinners  int
+	}{
+		{name: "activation code", text: "Разыграю код активации для Steam.", wantKey: true},
+		{name: "promo code", text: "Три промокода на подписку.", wantKey: true},
+		{name: "codex", text: "В кодексе ордена 3 местных правила."},
+		{name: "nintendo switch", text: "Картридж для Nintendo Switch.", wantNintendo: true},
+		{name: "switch in gameplay", text: "Нужно switch weapon вовремя."},
+		{name: "prize places", text: "Будет 3 места и 5 кодов.", wantWinners: 3},
+		{name: "codes count", text: "Разыграю 5 кодов активации.", wantKey: true, wantWinners: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExtractPrizes(models.Post{Text: tt.text})
+			if key := slices.Contains(got.Kinds, models.PrizeKey); key != tt.wantKey {
+				t.Errorf("ExtractPrizes(%q) key = %v, want %v", tt.text, key, tt.wantKey)
+			}
+			if nintendo := slices.Contains(got.Platforms, models.PlatformNintendo); nintendo != tt.wantNintendo {
+				t.Errorf("ExtractPrizes(%q) nintendo = %v, want %v", tt.text, nintendo, tt.wantNintendo)
+			}
+			if got.Winners != tt.wantWinners {
+				t.Errorf("ExtractPrizes(%q) winners = %d, want %d", tt.text, got.Winners, tt.wantWinners)
+			}
+		})
+	}
+}
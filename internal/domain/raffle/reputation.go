@@ -0,0 +1,79 @@
+package raffle
+
+import (
+	"dtf/game_draw/internal/domain/models"
+	"time"
+)
+
+const (
+	// raffle ended that long ago without results is counted as failed
+	// and is not waited for results anymore
+	resultsGracePeriod = 7 * 24 * time.Hour
+	// results within that delay are not penalized
+	fairResultsDelay = 3 * 24 * time.Hour
+	// penalty points for every day of delay beyond fair one
+	delayPenaltyPerDay = 2
+	maxDelayPenalty    = 20
+)
+
+// Reputations computes reputation of every author in raffle history
+func Reputations(history []models.Raffle, now time.Time) map[int]models.OrganizerReputation {
+	stats := make(map[int]models.OrganizerStats)
+	delays := make(map[int]time.Duration)
+
+	for _, r := range history {
+		s := stats[r.Author.Id]
+		s.Raffles++
+
+		switch {
+		case r.Results.IsPublished():
+			s.Finished++
+			s.WithResults++
+			delays[r.Author.Id] += resultsDelay(r)
+		case r.State == models.RaffleStateAbandoned,
+			r.State == models.RaffleStateEnded && now.Sub(r.StateChangedAt) > resultsGracePeriod:
+			s.Finished++
+		}
+		stats[r.Author.Id] = s
+	}
+
+	reputations := make(map[int]models.OrganizerReputation, len(stats))
+	for authorId, s := range stats {
+		if s.WithResults > 0 {
+			s.AvgResultsDelay = delays[authorId] / time.Duration(s.WithResults)
+		}
+		reputations[authorId] = models.OrganizerReputation{
+			Stats: s,
+			Score: trustScore(s),
+		}
+	}
+	return reputations
+}
+
+// trustScore is share of raffles with results, smoothed so a single raffle
+// doesn't make organizer fully trusted or untrusted, minus penalty for slow results
+func trustScore(s models.OrganizerStats) int {
+	if s.Finished == 0 {
+		return 0
+	}
+
+	score := float64(s.WithResults+1) / float64(s.Finished+2) * 100
+	if late := s.AvgResultsDelay - fairResultsDelay; late > 0 {
+		penalty := int(late.Hours()/24) * delayPenaltyPerDay
+		score -= float64(min(penalty, maxDelayPenalty))
+	}
+
+	return min(max(int(score), 0), 100)
+}
+
+// resultsDelay counts from deadline, raffles without one from publication
+func resultsDelay(r models.Raffle) time.Duration {
+	end := r.PublishedAt
+	if r.EndsAt.IsKnown() {
+		end = r.EndsAt.Time
+	}
+	if end.IsZero() {
+		return 0
+	}
+	return max(r.Results.PublishedAt.Sub(end), 0)
+}
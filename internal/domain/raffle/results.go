@@ -0,0 +1,154 @@
+package raffle
+
+import (
+	"dtf/game_draw/internal/domain/models"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// results posted much later than that are not searched for
+	maxResultsDelay = 60 * 24 * time.Hour
+	// minimal title similarity when author has several raffles
+	minTitleSimilarity = 0.25
+	// words are compared by prefix, so "ключей" and "ключи" are the same
+	stemLength = 4
+)
+
+var dtfLinkRe = regexp.MustCompile(`https?://(?:www\.)?dtf\.ru/[^\s"'<>()]+`)
+
+// words which are in every raffle and results title, they don't help matching
+var matchStopPatterns = []pattern{
+	newPattern("розыгрыш*"), newPattern("разыгр*"), newPattern("раздач*"), newPattern("конкурс*"),
+	newPattern("giveaway*"), newPattern("итог*"), newPattern("результат*"), newPattern("победител*"),
+	newPattern("завершен*"), newPattern("подвед*"),
+}
+
+// MatchResults finds the raffle results post belongs to.
+// Explicit link to the raffle wins, otherwise raffle of the same author
+// published before results with the most similar title is picked.
+// If author has the only raffle, its title has to share a word
+// with results title or text.
+func MatchResults(results models.Post, candidates []models.Raffle) (models.Raffle, bool) {
+	linked := LinkedPostIds(results)
+	for _, candidate := range candidates {
+		if linked[candidate.Id] {
+			return candidate, true
+		}
+	}
+
+	var sameAuthor []models.Raffle
+	for _, candidate := range candidates {
+		if candidate.Author.Id == 0 || candidate.Author.Id != results.Author.Id {
+			continue
+		}
+		if !results.PublishedAt.IsZero() && !candidate.PublishedAt.IsZero() {
+			delay := results.PublishedAt.Sub(candidate.PublishedAt)
+			if delay < 0 || delay > maxResultsDelay {
+				continue
+			}
+		}
+		sameAuthor = append(sameAuthor, candidate)
+	}
+
+	if len(sameAuthor) == 1 {
+		candidate := sameAuthor[0]
+		resultsStems := titleStems(results.Title + "\n" + results.Text)
+		for stem := range titleStems(candidate.Title) {
+			if resultsStems[stem] {
+				return candidate, true
+			}
+		}
+		return models.Raffle{}, false
+	}
+
+	var best models.Raffle
+	bestScore := minTitleSimilarity
+	found := false
+	resultsStems := titleStems(results.Title)
+	for _, candidate := range sameAuthor {
+		score := similarity(resultsStems, titleStems(candidate.Title))
+		if score > bestScore || (score == bestScore && !found) {
+			best, bestScore, found = candidate, score, true
+		}
+	}
+
+	return best, found
+}
+
+// LinkedPostIds returns ids of DTF posts linked in post text and blocks
+func LinkedPostIds(post models.Post) map[int64]bool {
+	var links []string
+	links = append(links, dtfLinkRe.FindAllString(post.Text, -1)...)
+	for _, block := range post.Blocks {
+		switch b := block.(type) {
+		case models.DataText:
+			links = append(links, dtfLinkRe.FindAllString(b.HtmlText, -1)...)
+		case models.DataLink:
+			links = append(links, b.Url)
+		case models.DataEmbed:
+			links = append(links, b.Url)
+		}
+	}
+
+	ids := make(map[int64]bool)
+	for _, link := range links {
+		if id, ok := dtfPostId(link); ok && id != post.Id {
+			ids[id] = true
+		}
+	}
+	return ids
+}
+
+// dtfPostId takes id from links like https://dtf.ru/games/1234567-slug,
+// the last path segment starting with a number is a post
+func dtfPostId(link string) (int64, bool) {
+	u, err := url.Parse(link)
+	if err != nil || !strings.HasSuffix(u.Hostname(), "dtf.ru") {
+		return 0, false
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		// user profiles look like /u/123-name, that's not a post
+		if i > 0 && segments[i-1] == "u" {
+			break
+		}
+		digits, _, _ := strings.Cut(segments[i], "-")
+		if id, err := strconv.ParseInt(digits, 10, 64); err == nil && id > 0 {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+func titleStems(title string) map[string]bool {
+	stems := make(map[string]bool)
+	for _, word := range words(title) {
+		if len([]rune(word)) < 3 || matchAny(matchStopPatterns, []string{word}) {
+			continue
+		}
+		if runes := []rune(word); len(runes) > stemLength {
+			word = string(runes[:stemLength])
+		}
+		stems[word] = true
+	}
+	return stems
+}
+
+// similarity is Jaccard index of two stem sets
+func similarity(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for stem := range a {
+		if b[stem] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
@@ -0,0 +1,78 @@
+package raffle
+
+import (
+	"dtf/game_draw/internal/domain/models"
+	"testing"
+	"time"
+)
+
+func TestMatchResults(t *testing.T) {
+	published := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	author := models.DtfUserInfo{Id: 7}
+	raffle := func(id int64, title string) models.Raffle {
+		return models.Raffle{Post: models.Post{Id: id, Title: title, Author: author, PublishedAt: published}}
+	}
+	results := func(title, text string) models.Post {
+		return models.Post{Id: 100, Title: title, Text: text, Author: author, PublishedAt: published.Add(72 * time.Hour)}
+	}
+
+	tests := []struct {
+		name       string
+		results    models.Post
+		candidates []models.Raffle
+		wantId     int64
+		wantFound  bool
+	}{
+		{
+			name:       "explicit link",
+			results:    results("Итоги", "Итоги розыгрыша https://dtf.ru/games/42-rozygrysh"),
+			candidates: []models.Raffle{raffle(41, "Розыгрыш Hades"), raffle(42, "Розыгрыш Cyberpunk 2077")},
+			wantId:     42,
+			wantFound:  true,
+		},
+		{
+			name:       "only raffle with similar title",
+			results:    results("Итоги розыгрыша Cyberpunk 2077", ""),
+			candidates: []models.Raffle{raffle(42, "Розыгрыш Cyberpunk 2077")},
+			wantId:     42,
+			wantFound:  true,
+		},
+		{
+			name:       "only raffle mentioned in text",
+			results:    results("Итоги розыгрыша", "Ключ от Cyberpunk уходит к @alex"),
+			candidates: []models.Raffle{raffle(42, "Розыгрыш Cyberpunk 2077")},
+			wantId:     42,
+			wantFound:  true,
+		},
+		{
+			name:       "only raffle with unrelated title",
+			results:    results("Итоги розыгрыша Hades 2", "Победитель @alex"),
+			candidates: []models.Raffle{raffle(42, "Розыгрыш Cyberpunk 2077")},
+			wantFound:  false,
+		},
+		{
+			name:       "most similar of several",
+			results:    results("Итоги: Hades 2", ""),
+			candidates: []models.Raffle{raffle(41, "Розыгрыш Hades 2"), raffle(42, "Розыгрыш Cyberpunk 2077")},
+			wantId:     41,
+			wantFound:  true,
+		},
+		{
+			name:    "other author",
+			results: results("Итоги розыгрыша Cyberpunk 2077", ""),
+			candidates: []models.Raffle{{Post: models.Post{
+				Id: 42, Title: "Розыгрыш Cyberpunk 2077", Author: models.DtfUserInfo{Id: 8}, PublishedAt: published,
+			}}},
+			wantFound: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := MatchResults(tt.results, tt.candidates)
+			if found != tt.wantFound || (found && got.Id != tt.wantId) {
+				t.Errorf("MatchResults() = %d, %v, want %d, %v", got.Id, found, tt.wantId, tt.wantFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package raffle
+
+import (
+	"dtf/game_draw/internal/domain/models"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	suspiciousRiskScore = 2
+	highRiskScore       = 5
+	// accounts younger than that at publication are new
+	newAccountAge = 30 * 24 * time.Hour
+)
+
+const (
+	credentialsWeight   = 5
+	lookalikeWeight     = 5
+	brandDomainWeight   = 1
+	shortenerWeight     = 3
+	paymentWeight       = 3
+	newAccountWeight    = 2
+	unknownDomainWeight = 1
+)
+
+var hrefRe = regexp.MustCompile(`href="([^"]+)"`)
+
+// domains raffles usually link to, subdomains are trusted too
+var trustedDomains = []string{
+	"dtf.ru",
+	"steampowered.com", "steamcommunity.com", "steamdb.info", "steamgifts.com", "gog.com", "epicgames.com",
+	"playstation.com", "xbox.com", "nintendo.com", "ea.com", "ubisoft.com",
+	"t.me", "telegram.me", "discord.gg", "discord.com", "vk.com",
+	"youtube.com", "youtu.be", "twitch.tv", "gleam.io",
+}
+
+var shortenerDomains = []string{
+	"bit.ly", "clck.ru", "tinyurl.com", "goo.su", "cutt.ly", "t.ly", "is.gd",
+	"vk.cc", "u.to", "shorturl.at", "rb.gy", "ow.ly", "tiny.cc",
+}
+
+// brand names phishing domains pretend to be
+var impersonatedBrands = []string{"steam", "valve", "epicgames", "playstation", "xbox", "discord"}
+
+// brand in domain is fine for fan sites (steamcharts.com), but not with these
+var (
+	suspiciousTlds = []string{
+		"xyz", "top", "site", "online", "click", "fun", "shop", "store", "icu",
+		"club", "live", "pw", "gift", "gifts", "win", "bond", "cfd", "sbs",
+	}
+	loginPathRe = regexp.MustCompile(`(?i)/(?:login|signin|sign-in|auth|oauth|openid|account|trade)`)
+)
+
+var (
+	credentialsPatterns = []pattern{
+		newPattern("логин* и парол*"), newPattern("парол* от*"), newPattern("свой парол*"),
+		newPattern("ваш парол*"), newPattern("your password"),
+		newPattern("steam guard"), newPattern("код* подтвержден*"), newPattern("код* из смс"),
+		newPattern("войд* через steam"), newPattern("войт* через steam"),
+		newPattern("авторизу* через steam"), newPattern("авторизац* через steam"),
+		newPattern("введ* свои данные"), newPattern("введит* данные"),
+	}
+	paymentPatterns = []pattern{
+		newPattern("оплатит*"), newPattern("оплати"), newPattern("предоплат*"), newPattern("комисси*"),
+		newPattern("переведи*"), newPattern("переведит*"), newPattern("перевод на карт*"),
+		newPattern("номер карт*"), newPattern("скинь* на карт*"), newPattern("задонат*"),
+	}
+)
+
+// AssessRisk looks for typical scam signs: phishing and shortened links,
+// requests for credentials or payments, brand-new author accounts.
+func AssessRisk(post models.Post) models.RaffleRisk {
+	var risk models.RaffleRisk
+	add := func(weight int, reason string) {
+		if slices.Contains(risk.Reasons, reason) {
+			return
+		}
+		risk.Score += weight
+		risk.Reasons = append(risk.Reasons, reason)
+	}
+
+	unknownDomain := false
+	for _, link := range postLinks(post) {
+		host := link.Hostname()
+		switch {
+		case matchesDomain(host, trustedDomains):
+			continue
+		case matchesDomain(host, shortenerDomains):
+			add(shortenerWeight, "сокращённая ссылка "+host)
+		case impersonates(host, link.Path):
+			add(lookalikeWeight, "поддельный домен "+host)
+		case hasBrand(host):
+			add(brandDomainWeight, "домен с названием бренда "+host)
+		case !unknownDomain:
+			unknownDomain = true
+			add(unknownDomainWeight, "ссылка на сторонний сайт "+host)
+		}
+	}
+
+	textWords := words(post.Title + "\n" + post.Text)
+	if matchAny(credentialsPatterns, textWords) {
+		add(credentialsWeight, "просят логин или пароль")
+	}
+	if matchAny(paymentPatterns, textWords) {
+		add(paymentWeight, "просят оплату")
+	}
+
+	created := post.Author.CreatedAt
+	if !created.IsZero() && !post.PublishedAt.IsZero() && post.PublishedAt.Sub(created) < newAccountAge {
+		days := int(post.PublishedAt.Sub(created).Hours() / 24)
+		add(newAccountWeight, fmt.Sprintf("аккаунту автора %d дн.", days))
+	}
+
+	switch {
+	case risk.Score >= highRiskScore:
+		risk.Level = models.RiskHigh
+	case risk.Score >= suspiciousRiskScore:
+		risk.Level = models.RiskSuspicious
+	}
+	return risk
+}
+
+// postLinks returns every link in post text and blocks,
+// hosts are lowercased and have no www prefix
+func postLinks(post models.Post) []*url.URL {
+	links := urlRe.FindAllString(post.Text, -1)
+	for _, block := range post.Blocks {
+		switch b := block.(type) {
+		case models.DataText:
+			for _, m := range hrefRe.FindAllStringSubmatch(b.HtmlText, -1) {
+				links = append(links, m[1])
+			}
+		case models.DataLink:
+			links = append(links, b.Url)
+		case models.DataEmbed:
+			links = append(links, b.Url)
+		}
+	}
+
+	var result []*url.URL
+	for _, link := range links {
+		if !strings.Contains(link, "://") {
+			link = "https://" + link
+		}
+		u, err := url.Parse(link)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		u.Host = strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+		result = append(result, u)
+	}
+	return result
+}
+
+func matchesDomain(host string, domains []string) bool {
+	for _, domain := range domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// impersonates catches typos of trusted domains (steamcommunnity.com),
+// trusted names in other zones (steam-gifts.ru) and brands on suspicious
+// zones or login pages (steam-promo.xyz, free-steam.ru/login)
+func impersonates(host, path string) bool {
+	name, tld := splitDomain(host)
+	registered := name + "." + tld
+	for _, domain := range trustedDomains {
+		if distance := editDistance(registered, domain); distance > 0 && distance <= maxTypos(domain) {
+			return true
+		}
+	}
+
+	if !hasBrand(host) {
+		return false
+	}
+	for _, domain := range trustedDomains {
+		if trustedName, _ := splitDomain(domain); strings.ReplaceAll(name, "-", "") == trustedName {
+			return true
+		}
+	}
+	return slices.Contains(suspiciousTlds, tld) || loginPathRe.MatchString(path)
+}
+
+// maxTypos is how many edits still look like the domain, short ones differ by a letter
+func maxTypos(domain string) int {
+	switch {
+	case len(domain) >= 12:
+		return 2
+	case len(domain) > 6:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// splitDomain returns second level name and top level zone
+func splitDomain(host string) (string, string) {
+	labels := strings.Split(host, ".")
+	if len(labels) < 2 {
+		return host, ""
+	}
+	return labels[len(labels)-2], labels[len(labels)-1]
+}
+
+func hasBrand(host string) bool {
+	host = strings.ReplaceAll(host, "-", "")
+	for _, brand := range impersonatedBrands {
+		if strings.Contains(host, brand) {
+			return true
+		}
+	}
+	return false
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
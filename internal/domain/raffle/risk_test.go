@@ -0,0 +1,84 @@
+package raffle
+
+import (
+	"dtf/game_draw/internal/domain/models"
+	"testing"
+	"time"
+)
+
+func TestAssessRiskLinks(t *testing.T) {
+	tests := []struct {
+		name string
+		link string
+		want models.RiskLevel
+	}{
+		{name: "trusted domain", link: "https://store.steampowered.com/app/1091500", want: models.RiskNone},
+		{name: "trusted subdomain", link: "https://www.xbox.com/ru-RU/games", want: models.RiskNone},
+		{name: "steam stats site", link: "https://steamcharts.com/app/1091500", want: models.RiskNone},
+		{name: "xbox achievements site", link: "https://www.xboxachievements.com/game/starfield", want: models.RiskNone},
+		{name: "playstation fan site", link: "https://playstationtrophies.org/game/bloodborne", want: models.RiskNone},
+		{name: "steam spy", link: "https://steamspy.com/app/570", want: models.RiskNone},
+		{name: "unknown site", link: "https://stopgame.ru/show/123", want: models.RiskNone},
+		{name: "one letter typo", link: "https://steamcommunnity.com/tradeoffer/new", want: models.RiskHigh},
+		{name: "two letter typo", link: "https://steampowerd.co/app/570", want: models.RiskHigh},
+		{name: "trusted name in other zone", link: "https://steam-gifts.ru/giveaway", want: models.RiskHigh},
+		{name: "brand on suspicious zone", link: "https://free-steam.xyz", want: models.RiskHigh},
+		{name: "brand with login page", link: "https://steam-promo.ru/login", want: models.RiskHigh},
+		{name: "discord nitro gift", link: "https://discord.gift/abcdef", want: models.RiskHigh},
+		{name: "shortener", link: "https://bit.ly/3abcd", want: models.RiskSuspicious},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := models.Post{Text: "Розыгрыш, подробности по ссылке " + tt.link}
+			got := AssessRisk(post)
+			if got.Level != tt.want {
+				t.Errorf("AssessRisk(%q) level = %v, want %v, reasons: %v", tt.link, got.Level, tt.want, got.Reasons)
+			}
+		})
+	}
+}
+
+func TestAssessRiskText(t *testing.T) {
+	published := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		post models.Post
+		want models.RiskLevel
+	}{
+		{
+			name: "usual raffle",
+			post: models.Post{Title: "Розыгрыш ключа", Text: "Лайк и коммент, итоги в пятницу"},
+			want: models.RiskNone,
+		},
+		{
+			name: "asks for credentials",
+			post: models.Post{Title: "Раздаю скины", Text: "Напишите в личку логин и пароль от Steam"},
+			want: models.RiskHigh,
+		},
+		{
+			name: "asks for payment",
+			post: models.Post{Title: "Розыгрыш", Text: "Чтобы получить ключ, оплатите комиссию 100 рублей"},
+			want: models.RiskSuspicious,
+		},
+		{
+			name: "new author account",
+			post: models.Post{
+				Title:       "Розыгрыш",
+				PublishedAt: published,
+				Author:      models.DtfUserInfo{CreatedAt: published.Add(-72 * time.Hour)},
+			},
+			want: models.RiskSuspicious,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AssessRisk(tt.post)
+			if got.Level != tt.want {
+				t.Errorf("AssessRisk() level = %v, want %v, reasons: %v", got.Level, tt.want, got.Reasons)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package raffle
+
+import (
+	"strings"
+	"unicode"
+)
+
+// normalize lowercases text and replaces ё with е
+func normalize(text string) string {
+	text = strings.ToLower(text)
+	return strings.ReplaceAll(text, "ё", "е")
+}
+
+// words splits normalized text into words, punctuation is dropped
+func words(text string) []string {
+	return strings.FieldsFunc(normalize(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// pattern is a sequence of words matched on word boundaries.
+// Word ending with * matches any word with such prefix:
+// "итог*" matches "итоги" and "итогов", but not "подытожим".
+type pattern []string
+
+func newPattern(phrase string) pattern {
+	return pattern(strings.Fields(normalize(phrase)))
+}
+
+func (p pattern) String() string {
+	return strings.Join(p, " ")
+}
+
+// count returns how many times pattern occurs in words
+func (p pattern) count(words []string) int {
+	if len(p) == 0 {
+		return 0
+	}
+
+	found := 0
+	for i := 0; i+len(p) <= len(words); i++ {
+		if p.matchAt(words, i) {
+			found++
+		}
+	}
+	return found
+}
+
+func (p pattern) matchAt(words []string, start int) bool {
+	for j, part := range p {
+		word := words[start+j]
+		if prefix, ok := strings.CutSuffix(part, "*"); ok {
+			if !strings.HasPrefix(word, prefix) {
+				return false
+			}
+			continue
+		}
+		if word != part {
+			return false
+		}
+	}
+	return true
+}
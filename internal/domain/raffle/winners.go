@@ -0,0 +1,89 @@
+package raffle
+
+import (
+	"dtf/game_draw/internal/domain/models"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// shorter names give too many false matches
+	minWinnerNameLength = 3
+	maxFragmentLength   = 200
+)
+
+var dtfProfileRe = regexp.MustCompile(`dtf\.ru/(?:u/|id)(\d+)`)
+
+// WinnerMatch is the line where account was found in results
+type WinnerMatch struct {
+	Fragment string
+	// found by plain name only, it may be another user with the same nickname
+	NameOnly bool
+}
+
+// FindWinner looks for account in results text line by line.
+// Profile links are checked first, then @mentions, then plain name.
+func FindWinner(text string, account models.DtfUserInfo) (WinnerMatch, bool) {
+	lines := strings.Split(text, "\n")
+	checks := []func(line string) bool{
+		func(line string) bool { return linksProfile(line, account) },
+		func(line string) bool { return mentions(line, account.Name) },
+	}
+
+	for _, check := range checks {
+		for _, line := range lines {
+			if check(line) {
+				return WinnerMatch{Fragment: fragment(line)}, true
+			}
+		}
+	}
+	for _, line := range lines {
+		if containsName(line, account.Name) {
+			return WinnerMatch{Fragment: fragment(line), NameOnly: true}, true
+		}
+	}
+	return WinnerMatch{}, false
+}
+
+func linksProfile(line string, account models.DtfUserInfo) bool {
+	for _, m := range dtfProfileRe.FindAllStringSubmatch(line, -1) {
+		if id, err := strconv.Atoi(m[1]); err == nil && id == account.Id && id != 0 {
+			return true
+		}
+	}
+
+	profile := strings.TrimPrefix(strings.ToLower(account.Url), "https://")
+	profile = strings.TrimPrefix(profile, "www.")
+	if profile == "" {
+		return false
+	}
+	// dtf.ru/id123 must not match dtf.ru/id1234
+	profileRe := regexp.MustCompile(regexp.QuoteMeta(profile) + `(?:[^\p{L}\d]|$)`)
+	return profileRe.MatchString(strings.ToLower(line))
+}
+
+func mentions(line, name string) bool {
+	if len([]rune(name)) < minWinnerNameLength {
+		return false
+	}
+	// @alex must not match @alexander
+	mentionRe := regexp.MustCompile(`@` + regexp.QuoteMeta(normalize(name)) + `(?:[^\p{L}\d_]|$)`)
+	return mentionRe.MatchString(normalize(line))
+}
+
+func containsName(line, name string) bool {
+	if len([]rune(name)) < minWinnerNameLength {
+		return false
+	}
+	namePattern := pattern(words(name))
+	return namePattern.count(words(line)) > 0
+}
+
+func fragment(line string) string {
+	line = strings.TrimSpace(line)
+	if runes := []rune(line); len(runes) > maxFragmentLength {
+		return string(runes[:maxFragmentLength]) + "…"
+	}
+	return line
+}
@@ -0,0 +1,45 @@
+package raffle
+
+import (
+	"dtf/game_draw/internal/domain/models"
+	"testing"
+)
+
+func TestFindWinner(t *testing.T) {
+	account := models.DtfUserInfo{Id: 123, Name: "Alex", Url: "https://dtf.ru/id123"}
+
+	tests := []struct {
+		name         string
+		text         string
+		wantFound    bool
+		wantNameOnly bool
+	}{
+		{name: "profile link", text: "Победитель: https://dtf.ru/id123", wantFound: true},
+		{name: "profile link with slug", text: "Ключ уходит dtf.ru/u/123-alex", wantFound: true},
+		{name: "profile url without scheme", text: "1. dtf.ru/id123, поздравляю", wantFound: true},
+		{name: "profile link of other id", text: "Победитель: https://dtf.ru/id1234", wantFound: false},
+		{name: "mention", text: "Победители: @alex и @kate", wantFound: true},
+		{name: "mention of longer nickname", text: "Победитель @alexander", wantFound: false},
+		{name: "bare name", text: "Победитель Alex, напиши в личку", wantFound: true, wantNameOnly: true},
+		{name: "not found", text: "Победители: @kate и @john", wantFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := FindWinner(tt.text, account)
+			if found != tt.wantFound || got.NameOnly != tt.wantNameOnly {
+				t.Errorf("FindWinner() = %+v, %v, want name only %v, %v", got, found, tt.wantNameOnly, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestFindWinnerPrefersProfileLink(t *testing.T) {
+	account := models.DtfUserInfo{Id: 123, Name: "Alex", Url: "https://dtf.ru/id123"}
+	text := "Спасибо Alex за помощь\nПобедитель: dtf.ru/id123"
+
+	got, found := FindWinner(text, account)
+	if !found || got.NameOnly || got.Fragment != "Победитель: dtf.ru/id123" {
+		t.Errorf("FindWinner() = %+v, %v, want profile link line", got, found)
+	}
+}
@@ -8,5 +8,6 @@ import (
 type AuthRepository interface {
 	Login(ctx context.Context, email, password string) (models.DtfUserSession, error)
 	RefreshToken(ctx context.Context, user models.DtfUserSession) (models.DtfUserSession, error)
+	EnsureValid(ctx context.Context, user models.DtfUserSession) (models.DtfUserSession, error)
 	SelfInfo(ctx context.Context, user models.DtfUserSession) (models.DtfUserInfo, error)
 }
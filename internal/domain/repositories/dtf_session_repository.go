@@ -8,8 +8,17 @@ import (
 type DtfSessionRepository interface {
 	// getters
 	GetByEmail(ctx context.Context, email string) (models.DtfUserSession, error)
+	GetAll(ctx context.Context) ([]models.DtfUserSession, error)
 
 	// mutators
 	Save(ctx context.Context, session models.DtfUserSession) error
+	// SaveRotated replaces tokens only if stored refresh token is still previousRefresh,
+	// otherwise returns domain.ErrSessionConflict
+	SaveRotated(ctx context.Context, previousRefresh string, session models.DtfUserSession) error
+	MarkNeedsRelogin(ctx context.Context, email string, reason string) error
+	// LinkTelegram links session to telegram subscriber, it gets session reports
+	LinkTelegram(ctx context.Context, email string, telegramId int64) error
+	// SetAutoParticipate switches sessions linked to telegram user, returns their count
+	SetAutoParticipate(ctx context.Context, telegramId int64, enabled bool) (int, error)
 	DeleteByEmail(ctx context.Context, email string) error
 }
@@ -8,6 +8,8 @@ import (
 
 type PostRepository interface {
 	SearchPosts(ctx context.Context, query string, dateFrom time.Time) ([]models.Post, error)
+	GetPostById(ctx context.Context, id int64) (models.Post, error)
+	GetComments(ctx context.Context, post models.Post) ([]models.Comment, error)
 	ReactToPost(ctx context.Context, user models.DtfUserSession, post models.Post) error
 	PostComment(ctx context.Context, user models.DtfUserSession, post models.Post, text string) error
 }
@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"context"
+	"time"
+)
+
+// RaffleDeliveryRepository remembers which raffles were sent to which subscriber
+type RaffleDeliveryRepository interface {
+	// getters
+	// GetDelivered returns ids of raffles already sent to subscriber
+	GetDelivered(ctx context.Context, telegramId int64) (map[int64]bool, error)
+	// GetRecipients returns subscribers raffle was sent to
+	GetRecipients(ctx context.Context, rafflePostId int64) ([]int64, error)
+
+	// mutators
+	// MarkDelivered ignores already delivered raffles
+	MarkDelivered(ctx context.Context, telegramId int64, rafflePostIds []int64, at time.Time) error
+}
@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"context"
+	"dtf/game_draw/internal/domain/models"
+)
+
+type RaffleParticipationRepository interface {
+	// getters
+	// GetParticipated returns ids of raffles account already took part in, skipped
+	// or failed for good, retrying ones are not included
+	GetParticipated(ctx context.Context, email string) (map[int64]bool, error)
+
+	// mutators
+	// Save ignores already known participation unless it is retrying
+	Save(ctx context.Context, participation models.RaffleParticipation) error
+	// RecordAttempt saves retrying participation, returns how many attempts failed
+	RecordAttempt(ctx context.Context, participation models.RaffleParticipation) (int, error)
+}
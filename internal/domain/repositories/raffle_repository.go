@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"context"
+	"dtf/game_draw/internal/domain/models"
+)
+
+type RaffleRepository interface {
+	// getters
+	GetById(ctx context.Context, postId int64) (models.Raffle, error)
+	GetByResultsPostId(ctx context.Context, resultsPostId int64) (models.Raffle, error)
+	GetByStates(ctx context.Context, states ...models.RaffleState) ([]models.Raffle, error)
+	GetByAuthors(ctx context.Context, authorIds ...int) ([]models.Raffle, error)
+
+	// mutators
+	// Save inserts new raffle with state new or refreshes post data of known one,
+	// state and first seen date of known raffle are kept
+	Save(ctx context.Context, raffle models.Raffle) (created bool, err error)
+	UpdateState(ctx context.Context, postId int64, state models.RaffleState) error
+	// UpdateCounters refreshes likes, comments, views and cover of known raffle
+	UpdateCounters(ctx context.Context, post models.Post) error
+	// SaveResults links results post and moves raffle to results published state
+	SaveResults(ctx context.Context, postId int64, results models.ResultsPost) error
+}
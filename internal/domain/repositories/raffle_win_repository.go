@@ -0,0 +1,11 @@
+package repositories
+
+import (
+	"context"
+	"dtf/game_draw/internal/domain/models"
+)
+
+type RaffleWinRepository interface {
+	Exists(ctx context.Context, rafflePostId int64, email string) (bool, error)
+	Save(ctx context.Context, win models.RaffleWin) error
+}
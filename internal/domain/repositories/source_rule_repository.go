@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+	"dtf/game_draw/internal/domain/models"
+)
+
+type SourceRuleRepository interface {
+	// getters
+	GetByOwners(ctx context.Context, owners ...int64) (models.SourceRules, error)
+
+	// mutators
+	// Save replaces mode of already existing rule
+	Save(ctx context.Context, rule models.SourceRule) error
+	// Delete returns domain.ErrSourceRuleNotFound if there is no such rule
+	Delete(ctx context.Context, owner int64, kind models.SourceKind, sourceId int) error
+}
@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+	"time"
+)
+
+// WatermarkRepository keeps points in time up to which data
+// was successfully processed, by name of the process
+type WatermarkRepository interface {
+	// Get returns domain.ErrWatermarkNotFound if process never succeeded
+	Get(ctx context.Context, name string) (time.Time, error)
+	Set(ctx context.Context, name string, watermark time.Time) error
+}
@@ -5,18 +5,14 @@ import (
 	"dtf/game_draw/internal/domain"
 	"dtf/game_draw/internal/domain/models"
 	"dtf/game_draw/internal/domain/repositories"
-	"dtf/game_draw/internal/utils"
 	"errors"
-	"fmt"
 	"log/slog"
-
-	"golang.org/x/sync/singleflight"
+	"time"
 )
 
 type userSessionManager struct {
-	sessionRepo  repositories.DtfSessionRepository
-	authRepo     repositories.AuthRepository
-	refreshGroup singleflight.Group
+	sessionRepo repositories.DtfSessionRepository
+	authRepo    repositories.AuthRepository
 }
 
 func NewUserSessionManager(
@@ -29,41 +25,103 @@ func NewUserSessionManager(
 	}
 }
 
+// BuildSession returns user session with valid access token.
+// Expired tokens are refreshed and persisted by auth repository.
+// Returns domain.ErrSessionExpired if user has to log in again.
 func (usm *userSessionManager) BuildSession(ctx context.Context, email string) (models.DtfUserSession, error) {
 	user, err := usm.sessionRepo.GetByEmail(ctx, email)
 	if err != nil {
 		return models.DtfUserSession{}, err
 	}
 
-	if !utils.UserExpired(user) {
-		return user, nil
+	if user.NeedsRelogin() {
+		return models.DtfUserSession{}, domain.ErrSessionExpired
 	}
 
-	// Avoid refresh token race conditions
-	// WARNING side effect: 1st executor will also place data in session repo
-	// other gorutines will only receive results
-	newUserAny, err, _ := usm.refreshGroup.Do(email, func() (interface{}, error) {
-		slog.Info("Running singleflight", "user", user.Email)
-		newUser, err := usm.authRepo.RefreshToken(ctx, user)
-		if err != nil {
-			return models.DtfUserSession{}, err
+	user, err = usm.authRepo.EnsureValid(ctx, user)
+	if err != nil {
+		usm.handleRefreshError(ctx, email, err)
+		return models.DtfUserSession{}, err
+	}
+
+	return user, nil
+}
+
+// RefreshExpiring refreshes every session which access or refresh token
+// expires within ahead duration. Should be called by scheduler
+// more often than ahead, so tokens never expire in the middle of the job.
+func (usm *userSessionManager) RefreshExpiring(ctx context.Context, ahead time.Duration) (models.SessionRefreshReport, error) {
+	var report models.SessionRefreshReport
+
+	sessions, err := usm.sessionRepo.GetAll(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	for _, session := range sessions {
+		if ctx.Err() != nil {
+			return report, ctx.Err()
 		}
 
-		if err := usm.persistUser(ctx, user); err != nil {
-			slog.Error("Failed to persist user", "email", user.Email, "error", err)
-			return models.DtfUserSession{}, err
+		if session.NeedsRelogin() {
+			report.NeedsRelogin = append(report.NeedsRelogin, session.Email)
+			continue
 		}
-		return newUser, nil
-	})
+		if !session.ExpiresWithin(ahead) {
+			continue
+		}
+
+		_, err := usm.authRepo.RefreshToken(ctx, session)
+		if err == nil {
+			report.Refreshed = append(report.Refreshed, session.Email)
+			continue
+		}
+
+		usm.handleRefreshError(ctx, session.Email, err)
+		if errors.Is(err, domain.ErrSessionExpired) {
+			report.NeedsRelogin = append(report.NeedsRelogin, session.Email)
+		} else {
+			report.Failed = append(report.Failed, session.Email)
+		}
+	}
+
+	return report, nil
+}
+
+func (usm *userSessionManager) SessionHealth(ctx context.Context, email string) (models.SessionHealth, error) {
+	session, err := usm.sessionRepo.GetByEmail(ctx, email)
 	if err != nil {
-		return models.DtfUserSession{}, err
+		return models.SessionHealth{}, err
+	}
+
+	return session.Health(), nil
+}
+
+func (usm *userSessionManager) SessionsHealth(ctx context.Context) ([]models.SessionHealth, error) {
+	sessions, err := usm.sessionRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.SessionHealth, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, session.Health())
+	}
+	return result, nil
+}
+
+// handleRefreshError marks session as needing re-login if refresh token is dead.
+// Other errors are temporary, session is kept as is.
+func (usm *userSessionManager) handleRefreshError(ctx context.Context, email string, err error) {
+	if !errors.Is(err, domain.ErrSessionExpired) {
+		slog.Warn("Session refresh failed", "email", email, "error", err)
+		return
 	}
 
-	newUser, ok := newUserAny.(models.DtfUserSession)
-	if !ok {
-		return models.DtfUserSession{}, fmt.Errorf("invalid type assertion for user session")
+	slog.Warn("Session needs re-login", "email", email, "error", err)
+	if err := usm.sessionRepo.MarkNeedsRelogin(ctx, email, err.Error()); err != nil {
+		slog.Error("Failed to mark session", "email", email, "error", err)
 	}
-	return newUser, nil
 }
 
 func (usm *userSessionManager) EmailLogin(ctx context.Context, email, password string) (models.DtfUserSession, error) {
@@ -2,22 +2,22 @@ package repositories
 
 import (
 	"context"
-	"dtf/game_draw/internal/domain"
 	"dtf/game_draw/internal/domain/models"
 	"dtf/game_draw/internal/domain/repositories"
 	"dtf/game_draw/pkg/dtfapi"
-	"errors"
 )
 
 type dtfAuthRepository struct {
-	dtfService *dtfapi.DtfService
+	dtfService   *dtfapi.DtfService
+	tokenSources *dtfTokenSources
 }
 
 var _ repositories.AuthRepository = (*dtfAuthRepository)(nil)
 
-func NewDtfAuthRepository(dtfService *dtfapi.DtfService) *dtfAuthRepository {
+func NewDtfAuthRepository(dtfService *dtfapi.DtfService, tokenSources *dtfTokenSources) *dtfAuthRepository {
 	return &dtfAuthRepository{
-		dtfService: dtfService,
+		dtfService:   dtfService,
+		tokenSources: tokenSources,
 	}
 }
 
@@ -25,39 +25,43 @@ func (r *dtfAuthRepository) Login(ctx context.Context, email, password string) (
 	tokens, err := r.dtfService.EmailLogin(ctx, email, password)
 
 	if err != nil {
-		if errors.Is(err, dtfapi.ErrInvalidCredentials) {
-			return models.DtfUserSession{}, domain.ErrInvalidCredentials
-		}
-		return models.DtfUserSession{}, err
+		return models.DtfUserSession{}, mapDtfError(err)
 	}
 
-	return models.DtfUserSession{
-		Email:            email,
-		AccessToken:      tokens.AccessToken,
-		RefreshToken:     tokens.RefreshToken,
-		AccessExpiration: tokens.AccessExpiration,
-	}, nil
+	// previous tokens of the user are not relevant anymore
+	r.tokenSources.Forget(email)
+
+	return tokensToSession(email, tokens), nil
 
 }
 
+// RefreshToken rotates user's tokens.
+// If they were already rotated by someone else, the newest ones are returned.
+// New tokens are persisted by token source.
 func (r *dtfAuthRepository) RefreshToken(ctx context.Context, user models.DtfUserSession) (models.DtfUserSession, error) {
-	tokens, err := r.dtfService.RefreshToken(ctx, user.RefreshToken)
+	tokens, err := r.tokenSources.For(user).Refresh(ctx, sessionToTokens(user))
 	if err != nil {
-		return models.DtfUserSession{}, err
+		return models.DtfUserSession{}, mapDtfError(err)
 	}
 
-	return models.DtfUserSession{
-		Email:            user.Email,
-		AccessToken:      tokens.AccessToken,
-		RefreshToken:     tokens.RefreshToken,
-		AccessExpiration: tokens.AccessExpiration,
-	}, nil
+	return tokensToSession(user.Email, tokens), nil
+}
+
+// EnsureValid returns session with valid access token,
+// refreshing and persisting tokens if needed.
+func (r *dtfAuthRepository) EnsureValid(ctx context.Context, user models.DtfUserSession) (models.DtfUserSession, error) {
+	tokens, err := r.tokenSources.For(user).Token(ctx)
+	if err != nil {
+		return models.DtfUserSession{}, mapDtfError(err)
+	}
+
+	return tokensToSession(user.Email, tokens), nil
 }
 
 func (r *dtfAuthRepository) SelfInfo(ctx context.Context, user models.DtfUserSession) (models.DtfUserInfo, error) {
-	response, err := r.dtfService.SelfUserInfo(ctx, user.AccessToken)
+	response, err := r.dtfService.SelfUserInfo(ctx, r.tokenSources.For(user))
 	if err != nil {
-		return models.DtfUserInfo{}, err
+		return models.DtfUserInfo{}, mapDtfError(err)
 	}
 
 	return models.DtfUserInfo{
@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"dtf/game_draw/internal/domain"
+	"dtf/game_draw/pkg/dtfapi"
+	"errors"
+	"fmt"
+)
+
+// mapDtfError converts dtfapi errors to domain ones.
+// Original error is kept in the chain.
+func mapDtfError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var domainErr error
+	switch {
+	case errors.Is(err, dtfapi.ErrInvalidCredentials):
+		domainErr = domain.ErrInvalidCredentials
+	case errors.Is(err, dtfapi.ErrRefreshTokenInvalid):
+		domainErr = domain.ErrSessionExpired
+	case errors.Is(err, dtfapi.ErrUnauthorized),
+		errors.Is(err, dtfapi.ErrTokenExpired),
+		errors.Is(err, dtfapi.ErrForbidden):
+		domainErr = domain.ErrUnauthorized
+	case errors.Is(err, dtfapi.ErrNotFound):
+		domainErr = domain.ErrPostNotFound
+	case errors.Is(err, dtfapi.ErrRateLimited):
+		domainErr = domain.ErrDtfRateLimited
+	case errors.Is(err, dtfapi.ErrServer),
+		errors.Is(err, dtfapi.ErrTransport):
+		domainErr = domain.ErrDtfUnavailable
+	default:
+		return err
+	}
+
+	return fmt.Errorf("%w: %w", domainErr, err)
+}
@@ -6,29 +6,33 @@ import (
 	"dtf/game_draw/internal/domain/repositories"
 	"dtf/game_draw/pkg/dtfapi"
 	"log/slog"
+	"strconv"
 	"time"
 )
 
 type dtfPostRepository struct {
-	dtfService *dtfapi.DtfService
+	dtfService   *dtfapi.DtfService
+	tokenSources *dtfTokenSources
 }
 
 var _ repositories.PostRepository = (*dtfPostRepository)(nil)
 
-func NewDtfPostRepository(dtfService *dtfapi.DtfService) *dtfPostRepository {
+func NewDtfPostRepository(dtfService *dtfapi.DtfService, tokenSources *dtfTokenSources) *dtfPostRepository {
 	return &dtfPostRepository{
-		dtfService: dtfService,
+		dtfService:   dtfService,
+		tokenSources: tokenSources,
 	}
 }
 
+// SearchPosts returns every post matching query since dateFrom,
+// going through all search pages.
 func (r dtfPostRepository) SearchPosts(ctx context.Context, query string, dateFrom time.Time) ([]models.Post, error) {
-	news, err := r.dtfService.SearchNews(ctx, query, dateFrom)
-	if err != nil {
-		return nil, err
-	}
+	var posts []models.Post
+	for newsItem, err := range r.dtfService.SearchNewsIter(ctx, query, dateFrom) {
+		if err != nil {
+			return nil, mapDtfError(err)
+		}
 
-	posts := make([]models.Post, 0, len(news))
-	for _, newsItem := range news {
 		post, err := models.FromDtfPost(newsItem)
 		if err != nil {
 			slog.Warn("Post can't be parsed.", "post", newsItem)
@@ -40,19 +44,41 @@ func (r dtfPostRepository) SearchPosts(ctx context.Context, query string, dateFr
 	return posts, nil
 }
 
+// GetPostById returns full post content, search results may be truncated
+func (r dtfPostRepository) GetPostById(ctx context.Context, id int64) (models.Post, error) {
+	blogPost, err := r.dtfService.GetPostById(ctx, strconv.FormatInt(id, 10))
+	if err != nil {
+		return models.Post{}, mapDtfError(err)
+	}
+
+	return models.FromDtfPost(blogPost)
+}
+
+func (r dtfPostRepository) GetComments(ctx context.Context, post models.Post) ([]models.Comment, error) {
+	var comments []models.Comment
+	for comment, err := range r.dtfService.GetCommentsIter(ctx, int(post.Id)) {
+		if err != nil {
+			return nil, mapDtfError(err)
+		}
+		comments = append(comments, models.FromDtfComment(comment))
+	}
+
+	return comments, nil
+}
+
 func (r dtfPostRepository) ReactToPost(ctx context.Context, user models.DtfUserSession, post models.Post) error {
-	err := r.dtfService.ReactToPost(ctx, user.AccessToken, int(post.Id))
+	err := r.dtfService.ReactToPost(ctx, r.tokenSources.For(user), int(post.Id))
 	if err != nil {
-		return err
+		return mapDtfError(err)
 	}
 
 	return nil
 }
 
 func (r dtfPostRepository) PostComment(ctx context.Context, user models.DtfUserSession, post models.Post, text string) error {
-	err := r.dtfService.PostComment(ctx, user.AccessToken, int(post.Id), text)
+	err := r.dtfService.PostComment(ctx, r.tokenSources.For(user), int(post.Id), text)
 	if err != nil {
-		return err
+		return mapDtfError(err)
 	}
 
 	return nil
@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"context"
+	"dtf/game_draw/internal/domain"
+	"dtf/game_draw/internal/domain/models"
+	"dtf/game_draw/internal/domain/repositories"
+	"dtf/game_draw/pkg/dtfapi"
+	"errors"
+	"sync"
+)
+
+// dtfTokenSources keeps one refreshing token source per user,
+// so concurrent requests of the same user never refresh tokens twice.
+// Rotated tokens are saved into session repository, if it already has
+// newer tokens (user logged in again), they are used instead.
+type dtfTokenSources struct {
+	dtfService  *dtfapi.DtfService
+	sessionRepo repositories.DtfSessionRepository
+
+	mu      sync.Mutex
+	sources map[string]*dtfapi.RefreshingTokenSource // by email
+}
+
+func NewDtfTokenSources(
+	dtfService *dtfapi.DtfService,
+	sessionRepo repositories.DtfSessionRepository,
+) *dtfTokenSources {
+	return &dtfTokenSources{
+		dtfService:  dtfService,
+		sessionRepo: sessionRepo,
+		sources:     make(map[string]*dtfapi.RefreshingTokenSource),
+	}
+}
+
+// For returns token source of the user.
+// Cached source is replaced if user has newer tokens, e.g. after login.
+func (p *dtfTokenSources) For(user models.DtfUserSession) *dtfapi.RefreshingTokenSource {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	source, ok := p.sources[user.Email]
+	if ok && !user.AccessExpiration.After(source.Tokens().AccessExpiration) {
+		return source
+	}
+
+	email := user.Email
+	source = dtfapi.NewRefreshingTokenSource(
+		p.dtfService,
+		sessionToTokens(user),
+		func(ctx context.Context, previous, current dtfapi.Tokens) (dtfapi.Tokens, error) {
+			return p.saveRotated(ctx, email, previous, current)
+		},
+	)
+	p.sources[user.Email] = source
+	return source
+}
+
+func (p *dtfTokenSources) saveRotated(
+	ctx context.Context,
+	email string,
+	previous, current dtfapi.Tokens,
+) (dtfapi.Tokens, error) {
+	err := p.sessionRepo.SaveRotated(ctx, previous.RefreshToken, tokensToSession(email, current))
+	if !errors.Is(err, domain.ErrSessionConflict) {
+		return current, err
+	}
+
+	stored, err := p.sessionRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return dtfapi.Tokens{}, err
+	}
+	return sessionToTokens(stored), nil
+}
+
+// Forget drops cached source, next For call creates a new one
+func (p *dtfTokenSources) Forget(email string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.sources, email)
+}
+
+func sessionToTokens(user models.DtfUserSession) dtfapi.Tokens {
+	return dtfapi.Tokens{
+		AccessToken:       user.AccessToken,
+		RefreshToken:      user.RefreshToken,
+		AccessExpiration:  user.AccessExpiration,
+		RefreshExpiration: user.RefreshExpiration,
+	}
+}
+
+func tokensToSession(email string, tokens dtfapi.Tokens) models.DtfUserSession {
+	return models.DtfUserSession{
+		Email:             email,
+		AccessToken:       tokens.AccessToken,
+		RefreshToken:      tokens.RefreshToken,
+		AccessExpiration:  tokens.AccessExpiration,
+		RefreshExpiration: tokens.RefreshExpiration,
+		Status:            models.SessionActive,
+	}
+}
@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"context"
+	"dtf/game_draw/internal/domain/models"
+	"dtf/game_draw/internal/storage"
+	"dtf/game_draw/internal/storage/sqlite/sqlitetest"
+	"dtf/game_draw/pkg/dtfapi"
+	"dtf/game_draw/pkg/dtfapi/dtfapitest"
+	"errors"
+	"testing"
+)
+
+const (
+	testEmail    = "user@example.com"
+	testPassword = "secret"
+)
+
+type tokenSourcesEnv struct {
+	server      *dtfapitest.Server
+	service     *dtfapi.DtfService
+	sessionRepo *SqliteUserSessionRepository
+}
+
+func newTokenSourcesEnv(t *testing.T) tokenSourcesEnv {
+	t.Helper()
+
+	server := dtfapitest.NewServer()
+	t.Cleanup(server.Close)
+	server.AddUser(dtfapitest.User{Name: "User", Email: testEmail, Password: testPassword})
+
+	client := dtfapi.NewClient(context.Background(), server.ClientOptions()...)
+	t.Cleanup(func() { _ = client.Close() })
+
+	return tokenSourcesEnv{
+		server:      server,
+		service:     dtfapi.NewService(client.Client()),
+		sessionRepo: NewSqliteUserSessionRepository(storage.NewProvider(sqlitetest.NewDB(t))),
+	}
+}
+
+// login creates a new api session and saves it like the user logged in via bot
+func (env tokenSourcesEnv) login(t *testing.T) models.DtfUserSession {
+	t.Helper()
+
+	tokens, err := env.service.EmailLogin(context.Background(), testEmail, testPassword)
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	session := tokensToSession(testEmail, tokens)
+	if err := env.sessionRepo.Save(context.Background(), session); err != nil {
+		t.Fatalf("save session: %v", err)
+	}
+	return session
+}
+
+func TestTokenSourcesSaveRotated(t *testing.T) {
+	ctx := context.Background()
+	env := newTokenSourcesEnv(t)
+	session := env.login(t)
+
+	sources := NewDtfTokenSources(env.service, env.sessionRepo)
+	tokens, err := sources.For(session).Refresh(ctx, sessionToTokens(session))
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if tokens.RefreshToken == session.RefreshToken {
+		t.Fatal("refresh token wasn't rotated")
+	}
+
+	stored, err := env.sessionRepo.GetByEmail(ctx, testEmail)
+	if err != nil {
+		t.Fatalf("get session: %v", err)
+	}
+	if stored.RefreshToken != tokens.RefreshToken {
+		t.Errorf("stored refresh token = %q, want rotated %q", stored.RefreshToken, tokens.RefreshToken)
+	}
+}
+
+func TestTokenSourcesAdoptStoredOnConflict(t *testing.T) {
+	ctx := context.Background()
+	env := newTokenSourcesEnv(t)
+	session := env.login(t)
+
+	sources := NewDtfTokenSources(env.service, env.sessionRepo)
+	source := sources.For(session)
+
+	// user logs in again while cached source still has the old pair
+	relogin := env.login(t)
+
+	tokens, err := source.Refresh(ctx, sessionToTokens(session))
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if tokens.RefreshToken != relogin.RefreshToken {
+		t.Errorf("refresh token = %q, want stored %q", tokens.RefreshToken, relogin.RefreshToken)
+	}
+
+	stored, err := env.sessionRepo.GetByEmail(ctx, testEmail)
+	if err != nil {
+		t.Fatalf("get session: %v", err)
+	}
+	if stored.RefreshToken != relogin.RefreshToken {
+		t.Errorf("stored refresh token = %q, want %q", stored.RefreshToken, relogin.RefreshToken)
+	}
+}
+
+// failingSessionRepo fails SaveRotated while fail is set
+type failingSessionRepo struct {
+	*SqliteUserSessionRepository
+	fail bool
+}
+
+var errSaveFailed = errors.New("disk is full")
+
+func (r *failingSessionRepo) SaveRotated(ctx context.Context, previousRefresh string, session models.DtfUserSession) error {
+	if r.fail {
+		return errSaveFailed
+	}
+	return r.SqliteUserSessionRepository.SaveRotated(ctx, previousRefresh, session)
+}
+
+func TestTokenSourcesRetrySave(t *testing.T) {
+	ctx := context.Background()
+	env := newTokenSourcesEnv(t)
+	session := env.login(t)
+
+	repo := &failingSessionRepo{SqliteUserSessionRepository: env.sessionRepo, fail: true}
+	source := NewDtfTokenSources(env.service, repo).For(session)
+
+	if _, err := source.Refresh(ctx, sessionToTokens(session)); !errors.Is(err, errSaveFailed) {
+		t.Fatalf("refresh error = %v, want %v", err, errSaveFailed)
+	}
+
+	// rotated tokens are kept in memory and saved by the next call
+	repo.fail = false
+	tokens, err := source.Token(ctx)
+	if err != nil {
+		t.Fatalf("token: %v", err)
+	}
+	if tokens.RefreshToken == session.RefreshToken {
+		t.Fatal("refresh token wasn't rotated")
+	}
+
+	stored, err := env.sessionRepo.GetByEmail(ctx, testEmail)
+	if err != nil {
+		t.Fatalf("get session: %v", err)
+	}
+	if stored.RefreshToken != tokens.RefreshToken {
+		t.Errorf("stored refresh token = %q, want %q", stored.RefreshToken, tokens.RefreshToken)
+	}
+}
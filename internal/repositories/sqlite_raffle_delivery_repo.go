@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"dtf/game_draw/internal/domain"
+	"dtf/game_draw/internal/domain/repositories"
+	"dtf/game_draw/internal/storage"
+	"dtf/game_draw/internal/storage/sqlite"
+	"fmt"
+	"time"
+)
+
+const raffleDeliveriesTableName = "raffle_deliveries"
+
+var _ repositories.RaffleDeliveryRepository = (*SqliteRaffleDeliveryRepository)(nil)
+
+type SqliteRaffleDeliveryRepository struct {
+	dbProvider *storage.Provider
+	transactor domain.Transactor
+}
+
+func NewSqliteRaffleDeliveryRepository(dbProvider *storage.Provider, transactor domain.Transactor) *SqliteRaffleDeliveryRepository {
+	return &SqliteRaffleDeliveryRepository{
+		dbProvider: dbProvider,
+		transactor: transactor,
+	}
+}
+
+func (r *SqliteRaffleDeliveryRepository) GetDelivered(ctx context.Context, telegramId int64) (map[int64]bool, error) {
+	query := fmt.Sprintf(`
+		SELECT raffle_post_id
+		FROM %s
+		WHERE telegram_id = ?;
+	`, raffleDeliveriesTableName)
+
+	ids, err := r.queryIds(ctx, query, telegramId)
+	if err != nil {
+		return nil, err
+	}
+
+	delivered := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		delivered[id] = true
+	}
+	return delivered, nil
+}
+
+func (r *SqliteRaffleDeliveryRepository) GetRecipients(ctx context.Context, rafflePostId int64) ([]int64, error) {
+	// unsubscribed users are not bothered anymore
+	query := fmt.Sprintf(`
+		SELECT d.telegram_id
+		FROM %s d
+		JOIN %s s ON s.telegram_id = d.telegram_id
+		WHERE d.raffle_post_id = ?;
+	`, raffleDeliveriesTableName, dbTableName)
+
+	return r.queryIds(ctx, query, rafflePostId)
+}
+
+func (r *SqliteRaffleDeliveryRepository) MarkDelivered(
+	ctx context.Context,
+	telegramId int64,
+	rafflePostIds []int64,
+	at time.Time,
+) error {
+	if len(rafflePostIds) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (raffle_post_id, telegram_id, delivered_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (raffle_post_id, telegram_id) DO NOTHING;
+	`, raffleDeliveriesTableName)
+
+	return r.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		for _, id := range rafflePostIds {
+			_, err := r.dbProvider.Ext(ctx).ExecContext(ctx, query, id, telegramId, sqlite.ToDbTime(at))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *SqliteRaffleDeliveryRepository) queryIds(ctx context.Context, query string, args ...any) ([]int64, error) {
+	rows, err := r.dbProvider.Ext(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return ids, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return ids, err
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+	"dtf/game_draw/internal/domain/models"
+	"dtf/game_draw/internal/domain/repositories"
+	"dtf/game_draw/internal/storage"
+	"dtf/game_draw/internal/storage/sqlite"
+	"fmt"
+)
+
+const raffleParticipationsTableName = "raffle_participations"
+
+var _ repositories.RaffleParticipationRepository = (*SqliteRaffleParticipationRepository)(nil)
+
+type SqliteRaffleParticipationRepository struct {
+	dbProvider *storage.Provider
+}
+
+func NewSqliteRaffleParticipationRepository(dbProvider *storage.Provider) *SqliteRaffleParticipationRepository {
+	return &SqliteRaffleParticipationRepository{
+		dbProvider: dbProvider,
+	}
+}
+
+func (r *SqliteRaffleParticipationRepository) GetParticipated(ctx context.Context, email string) (map[int64]bool, error) {
+	query := fmt.Sprintf(`
+		SELECT raffle_post_id
+		FROM %s
+		WHERE email = ? AND status != ?;
+	`, raffleParticipationsTableName)
+
+	rows, err := r.dbProvider.Ext(ctx).QueryContext(ctx, query, email, string(models.ParticipationRetrying))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	participated := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return participated, err
+		}
+		participated[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return participated, err
+	}
+
+	return participated, nil
+}
+
+// Save replaces only retrying participation, final ones are kept
+func (r *SqliteRaffleParticipationRepository) Save(ctx context.Context, p models.RaffleParticipation) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %[1]s (raffle_post_id, email, status, liked, commented, comment_text, reason, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (raffle_post_id, email) DO UPDATE SET
+			status = excluded.status,
+			liked = excluded.liked,
+			commented = excluded.commented,
+			comment_text = excluded.comment_text,
+			reason = excluded.reason,
+			created_at = excluded.created_at
+		WHERE %[1]s.status = '%[2]s';
+	`, raffleParticipationsTableName, models.ParticipationRetrying)
+
+	_, err := r.dbProvider.Ext(ctx).ExecContext(
+		ctx,
+		query,
+		p.Raffle.Id,
+		p.Email,
+		string(p.Status),
+		p.Participation.Liked,
+		p.Participation.Commented,
+		p.Participation.CommentText,
+		p.Reason,
+		sqlite.ToDbTime(p.CreatedAt),
+	)
+	return err
+}
+
+// RecordAttempt saves failed attempt of participation which is retried later
+func (r *SqliteRaffleParticipationRepository) RecordAttempt(ctx context.Context, p models.RaffleParticipation) (int, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %[1]s (raffle_post_id, email, status, reason, created_at, attempts)
+		VALUES (?, ?, ?, ?, ?, 1)
+		ON CONFLICT (raffle_post_id, email) DO UPDATE SET
+			attempts = %[1]s.attempts + 1,
+			reason = excluded.reason
+		WHERE %[1]s.status = excluded.status;
+	`, raffleParticipationsTableName)
+
+	_, err := r.dbProvider.Ext(ctx).ExecContext(
+		ctx,
+		query,
+		p.Raffle.Id,
+		p.Email,
+		string(models.ParticipationRetrying),
+		p.Reason,
+		sqlite.ToDbTime(p.CreatedAt),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var attempts int
+	err = r.dbProvider.Ext(ctx).QueryRowContext(
+		ctx,
+		fmt.Sprintf(`SELECT attempts FROM %s WHERE raffle_post_id = ? AND email = ?;`, raffleParticipationsTableName),
+		p.Raffle.Id,
+		p.Email,
+	).Scan(&attempts)
+	return attempts, err
+}
@@ -0,0 +1,395 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"dtf/game_draw/internal/domain"
+	"dtf/game_draw/internal/domain/models"
+	"dtf/game_draw/internal/domain/repositories"
+	"dtf/game_draw/internal/storage"
+	"dtf/game_draw/internal/storage/sqlite"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const rafflesTableName = "raffles"
+
+// columns written by Save
+const raffleDataColumns = `post_id, title, uri, text, author_id, author_name, author_created_at,
+	subsite_id, subsite_name, published_at, ends_at, ends_at_confidence, ends_at_date_only,
+	state, first_seen_at, state_changed_at, likes, comments, views, cover_url`
+
+const raffleColumns = raffleDataColumns + `,
+	results_post_id, results_title, results_uri, results_text, results_published_at`
+
+var _ repositories.RaffleRepository = (*SqliteRaffleRepository)(nil)
+
+type SqliteRaffleRepository struct {
+	dbProvider *storage.Provider
+	transactor domain.Transactor
+}
+
+func NewSqliteRaffleRepository(dbProvider *storage.Provider, transactor domain.Transactor) *SqliteRaffleRepository {
+	return &SqliteRaffleRepository{
+		dbProvider: dbProvider,
+		transactor: transactor,
+	}
+}
+
+func scanRaffle(row rowScanner) (models.Raffle, error) {
+	var raffle models.Raffle
+	var authorCreatedAt, publishedAt, endsAt, resultsPublishedAt sql.NullString
+	var resultsPostId sql.NullInt64
+	var firstSeenAt, stateChangedAt, state string
+	var confidence int
+
+	err := row.Scan(
+		&raffle.Id,
+		&raffle.Title,
+		&raffle.Uri,
+		&raffle.Text,
+		&raffle.Author.Id,
+		&raffle.Author.Name,
+		&authorCreatedAt,
+		&raffle.Subsite.Id,
+		&raffle.Subsite.Name,
+		&publishedAt,
+		&endsAt,
+		&confidence,
+		&raffle.EndsAt.DateOnly,
+		&state,
+		&firstSeenAt,
+		&stateChangedAt,
+		&raffle.Likes,
+		&raffle.Comments,
+		&raffle.Views,
+		&raffle.CoverUrl,
+		&resultsPostId,
+		&raffle.Results.Title,
+		&raffle.Results.Uri,
+		&raffle.Results.Text,
+		&resultsPublishedAt,
+	)
+	if err != nil {
+		return raffle, err
+	}
+	raffle.Results.PostId = resultsPostId.Int64
+	raffle.State = models.RaffleState(state)
+	raffle.EndsAt.Confidence = models.DeadlineConfidence(confidence)
+
+	if raffle.Author.CreatedAt, err = sqlite.FromNullDbTime(authorCreatedAt); err != nil {
+		return raffle, err
+	}
+	if raffle.PublishedAt, err = sqlite.FromNullDbTime(publishedAt); err != nil {
+		return raffle, err
+	}
+	if raffle.EndsAt.Time, err = sqlite.FromNullDbTime(endsAt); err != nil {
+		return raffle, err
+	}
+	if raffle.FirstSeenAt, err = sqlite.FromDbTime(firstSeenAt); err != nil {
+		return raffle, err
+	}
+	if raffle.StateChangedAt, err = sqlite.FromDbTime(stateChangedAt); err != nil {
+		return raffle, err
+	}
+	if raffle.Results.PublishedAt, err = sqlite.FromNullDbTime(resultsPublishedAt); err != nil {
+		return raffle, err
+	}
+
+	return raffle, nil
+}
+
+func (r *SqliteRaffleRepository) GetById(ctx context.Context, postId int64) (models.Raffle, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE post_id = ?
+		LIMIT 1;
+	`, raffleColumns, rafflesTableName)
+
+	raffle, err := scanRaffle(r.dbProvider.Ext(ctx).QueryRowContext(ctx, query, postId))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return raffle, domain.ErrRaffleNotFound
+		}
+		return raffle, err
+	}
+
+	return raffle, nil
+}
+
+func (r *SqliteRaffleRepository) GetByResultsPostId(ctx context.Context, resultsPostId int64) (models.Raffle, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE results_post_id = ?
+		LIMIT 1;
+	`, raffleColumns, rafflesTableName)
+
+	raffle, err := scanRaffle(r.dbProvider.Ext(ctx).QueryRowContext(ctx, query, resultsPostId))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return raffle, domain.ErrRaffleNotFound
+		}
+		return raffle, err
+	}
+
+	return raffle, nil
+}
+
+func (r *SqliteRaffleRepository) GetByStates(
+	ctx context.Context,
+	states ...models.RaffleState,
+) ([]models.Raffle, error) {
+	if len(states) == 0 {
+		return nil, nil
+	}
+
+	args := make([]any, len(states))
+	for i, state := range states {
+		args[i] = string(state)
+	}
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE state IN (%s)
+		ORDER BY published_at DESC;
+	`, raffleColumns, rafflesTableName, placeholders(len(states)))
+
+	return r.query(ctx, query, args...)
+}
+
+func (r *SqliteRaffleRepository) GetByAuthors(ctx context.Context, authorIds ...int) ([]models.Raffle, error) {
+	if len(authorIds) == 0 {
+		return nil, nil
+	}
+
+	args := make([]any, len(authorIds))
+	for i, id := range authorIds {
+		args[i] = id
+	}
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE author_id IN (%s)
+		ORDER BY published_at DESC;
+	`, raffleColumns, rafflesTableName, placeholders(len(authorIds)))
+
+	return r.query(ctx, query, args...)
+}
+
+func (r *SqliteRaffleRepository) Save(ctx context.Context, raffle models.Raffle) (bool, error) {
+	created := false
+	err := r.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		now := sqlite.ToDbTime(time.Now())
+
+		stored, err := r.GetById(ctx, raffle.Id)
+		if err != nil && !errors.Is(err, domain.ErrRaffleNotFound) {
+			return err
+		}
+
+		if errors.Is(err, domain.ErrRaffleNotFound) {
+			created = true
+			query := fmt.Sprintf(`
+				INSERT INTO %s (%s, updated_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+			`, rafflesTableName, raffleDataColumns)
+
+			_, err = r.dbProvider.Ext(ctx).ExecContext(
+				ctx,
+				query,
+				raffle.Id,
+				raffle.Title,
+				raffle.Uri,
+				raffle.Text,
+				raffle.Author.Id,
+				raffle.Author.Name,
+				sqlite.ToNullDbTime(raffle.Author.CreatedAt),
+				raffle.Subsite.Id,
+				raffle.Subsite.Name,
+				sqlite.ToNullDbTime(raffle.PublishedAt),
+				sqlite.ToNullDbTime(raffle.EndsAt.Time),
+				int(raffle.EndsAt.Confidence),
+				raffle.EndsAt.DateOnly,
+				string(models.RaffleStateNew),
+				now,
+				now,
+				raffle.Likes,
+				raffle.Comments,
+				raffle.Views,
+				raffle.CoverUrl,
+				now,
+			)
+			return err
+		}
+
+		// post could be edited without a deadline, keeping the old one then
+		endsAt := raffle.EndsAt
+		if !endsAt.IsKnown() {
+			endsAt = stored.EndsAt
+		}
+
+		query := fmt.Sprintf(`
+			UPDATE %s
+			SET title = ?, uri = ?, text = ?, author_id = ?, author_name = ?,
+				author_created_at = COALESCE(?, author_created_at), subsite_id = ?, subsite_name = ?, published_at = ?,
+				ends_at = ?, ends_at_confidence = ?, ends_at_date_only = ?,
+				likes = ?, comments = ?, views = ?, cover_url = ?,
+				updated_at = ?
+			WHERE post_id = ?;
+		`, rafflesTableName)
+
+		_, err = r.dbProvider.Ext(ctx).ExecContext(
+			ctx,
+			query,
+			raffle.Title,
+			raffle.Uri,
+			raffle.Text,
+			raffle.Author.Id,
+			raffle.Author.Name,
+			sqlite.ToNullDbTime(raffle.Author.CreatedAt),
+			raffle.Subsite.Id,
+			raffle.Subsite.Name,
+			sqlite.ToNullDbTime(raffle.PublishedAt),
+			sqlite.ToNullDbTime(endsAt.Time),
+			int(endsAt.Confidence),
+			endsAt.DateOnly,
+			raffle.Likes,
+			raffle.Comments,
+			raffle.Views,
+			raffle.CoverUrl,
+			now,
+			raffle.Id,
+		)
+		return err
+	})
+
+	return created, err
+}
+
+func (r *SqliteRaffleRepository) UpdateState(
+	ctx context.Context,
+	postId int64,
+	state models.RaffleState,
+) error {
+	now := sqlite.ToDbTime(time.Now())
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET state = ?, state_changed_at = ?, updated_at = ?
+		WHERE post_id = ?;
+	`, rafflesTableName)
+
+	res, err := r.dbProvider.Ext(ctx).ExecContext(ctx, query, string(state), now, now, postId)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrRaffleNotFound
+	}
+
+	return nil
+}
+
+func (r *SqliteRaffleRepository) UpdateCounters(ctx context.Context, post models.Post) error {
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET likes = ?, comments = ?, views = ?, cover_url = ?, updated_at = ?
+		WHERE post_id = ?;
+	`, rafflesTableName)
+
+	res, err := r.dbProvider.Ext(ctx).ExecContext(
+		ctx,
+		query,
+		post.Likes,
+		post.Comments,
+		post.Views,
+		post.CoverUrl,
+		sqlite.ToDbTime(time.Now()),
+		post.Id,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrRaffleNotFound
+	}
+
+	return nil
+}
+
+func (r *SqliteRaffleRepository) SaveResults(
+	ctx context.Context,
+	postId int64,
+	results models.ResultsPost,
+) error {
+	now := sqlite.ToDbTime(time.Now())
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET results_post_id = ?, results_title = ?, results_uri = ?, results_text = ?,
+			results_published_at = ?, state = ?, state_changed_at = ?, updated_at = ?
+		WHERE post_id = ?;
+	`, rafflesTableName)
+
+	res, err := r.dbProvider.Ext(ctx).ExecContext(
+		ctx,
+		query,
+		results.PostId,
+		results.Title,
+		results.Uri,
+		results.Text,
+		sqlite.ToNullDbTime(results.PublishedAt),
+		string(models.RaffleStateResultsPublished),
+		now,
+		now,
+		postId,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrRaffleNotFound
+	}
+
+	return nil
+}
+
+func (r *SqliteRaffleRepository) query(ctx context.Context, query string, args ...any) ([]models.Raffle, error) {
+	rows, err := r.dbProvider.Ext(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var raffles []models.Raffle
+	for rows.Next() {
+		raffle, err := scanRaffle(rows)
+		if err != nil {
+			return raffles, err
+		}
+		raffles = append(raffles, raffle)
+	}
+	if err := rows.Err(); err != nil {
+		return raffles, err
+	}
+
+	return raffles, nil
+}
+
+// placeholders returns "?, ?, ?" for IN clauses
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
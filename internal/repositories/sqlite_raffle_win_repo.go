@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"dtf/game_draw/internal/domain/models"
+	"dtf/game_draw/internal/domain/repositories"
+	"dtf/game_draw/internal/storage"
+	"dtf/game_draw/internal/storage/sqlite"
+	"fmt"
+)
+
+const raffleWinsTableName = "raffle_wins"
+
+var _ repositories.RaffleWinRepository = (*SqliteRaffleWinRepository)(nil)
+
+type SqliteRaffleWinRepository struct {
+	dbProvider *storage.Provider
+}
+
+func NewSqliteRaffleWinRepository(dbProvider *storage.Provider) *SqliteRaffleWinRepository {
+	return &SqliteRaffleWinRepository{
+		dbProvider: dbProvider,
+	}
+}
+
+func (r *SqliteRaffleWinRepository) Exists(ctx context.Context, rafflePostId int64, email string) (bool, error) {
+	query := fmt.Sprintf(`
+		SELECT EXISTS (
+			SELECT 1 FROM %s
+			WHERE raffle_post_id = ? AND email = ?
+		);
+	`, raffleWinsTableName)
+
+	var exists bool
+	err := r.dbProvider.Ext(ctx).QueryRowContext(ctx, query, rafflePostId, email).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// Save ignores already known wins
+func (r *SqliteRaffleWinRepository) Save(ctx context.Context, win models.RaffleWin) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (raffle_post_id, email, dtf_user_id, fragment, uri, found_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (raffle_post_id, email) DO NOTHING;
+	`, raffleWinsTableName)
+
+	_, err := r.dbProvider.Ext(ctx).ExecContext(
+		ctx,
+		query,
+		win.RafflePostId,
+		win.Email,
+		win.DtfUser.Id,
+		win.Fragment,
+		win.Uri,
+		sqlite.ToDbTime(win.FoundAt),
+	)
+	return err
+}
@@ -8,12 +8,18 @@ import (
 	"dtf/game_draw/internal/domain/repositories"
 	"dtf/game_draw/internal/storage"
 	"dtf/game_draw/internal/storage/sqlite"
+	"errors"
 	"fmt"
 	"time"
 )
 
 const sqliteTableName = "user_sessions"
 
+// telegram id is read from linked subscriber, 0 if not linked
+const sessionColumns = `email, access, refresh, access_expiration, refresh_expiration,
+	status, last_refreshed_at, last_error, auto_participate,
+	(SELECT telegram_id FROM telegram_subscribers WHERE telegram_subscribers.id = user_sessions.telegram_subscriber_id)`
+
 var _ repositories.DtfSessionRepository = (*SqliteUserSessionRepository)(nil)
 
 type SqliteUserSessionRepository struct {
@@ -26,27 +32,62 @@ func NewSqliteUserSessionRepository(dbProvider *storage.Provider) *SqliteUserSes
 	}
 }
 
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSession(row rowScanner) (models.DtfUserSession, error) {
+	var session models.DtfUserSession
+	var accessExpirationString string
+	var refreshExpiration, lastRefreshedAt sql.NullString
+	var telegramId sql.NullInt64
+	var status string
+
+	err := row.Scan(
+		&session.Email,
+		&session.AccessToken,
+		&session.RefreshToken,
+		&accessExpirationString,
+		&refreshExpiration,
+		&status,
+		&lastRefreshedAt,
+		&session.LastError,
+		&session.AutoParticipate,
+		&telegramId,
+	)
+	if err != nil {
+		return session, err
+	}
+	session.Status = models.SessionStatus(status)
+	session.TelegramId = telegramId.Int64
+
+	if session.AccessExpiration, err = sqlite.FromDbTime(accessExpirationString); err != nil {
+		return session, err
+	}
+	if session.RefreshExpiration, err = sqlite.FromNullDbTime(refreshExpiration); err != nil {
+		return session, err
+	}
+	if session.LastRefreshedAt, err = sqlite.FromNullDbTime(lastRefreshedAt); err != nil {
+		return session, err
+	}
+
+	return session, nil
+}
+
 func (repo *SqliteUserSessionRepository) GetByEmail(
 	ctx context.Context,
 	email string,
 ) (models.DtfUserSession, error) {
-	var session models.DtfUserSession
-	var accessExpirationString string
 	queryStr := fmt.Sprintf(`
-		SELECT email, access, refresh, access_expiration
+		SELECT %s
 		FROM %s
 		WHERE email = ?
 		LIMIT 1;
-	`, sqliteTableName)
+	`, sessionColumns, sqliteTableName)
 
 	row := repo.dbProvider.Ext(ctx).QueryRowContext(ctx, queryStr, email)
 
-	err := row.Scan(
-		&session.Email,
-		&session.AccessToken,
-		&session.RefreshToken,
-		&accessExpirationString,
-	)
+	session, err := scanSession(row)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return session, domain.ErrUserSessionNotFound
@@ -54,26 +95,54 @@ func (repo *SqliteUserSessionRepository) GetByEmail(
 		return session, err
 	}
 
-	session.AccessExpiration, err = sqlite.FromDbTime(accessExpirationString)
+	return session, nil
+}
+
+func (repo *SqliteUserSessionRepository) GetAll(ctx context.Context) ([]models.DtfUserSession, error) {
+	queryStr := fmt.Sprintf(`
+		SELECT %s
+		FROM %s;
+	`, sessionColumns, sqliteTableName)
 
+	rows, err := repo.dbProvider.Ext(ctx).QueryContext(ctx, queryStr)
 	if err != nil {
-		return session, err
+		return nil, err
 	}
+	defer rows.Close()
 
-	return session, nil
+	var sessions []models.DtfUserSession
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return sessions, err
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return sessions, err
+	}
+
+	return sessions, nil
 }
 
+// Save creates or replaces the session, e.g. after login.
+// Session becomes active.
 func (repo *SqliteUserSessionRepository) Save(
 	ctx context.Context,
 	us models.DtfUserSession,
 ) error {
 	queryStr := fmt.Sprintf(`
-	INSERT INTO %s (email, access, refresh, access_expiration, created_at, updated_at)
-		VALUES(?, ?, ?, ?, ?, ?)
+	INSERT INTO %s (email, access, refresh, access_expiration, refresh_expiration,
+		status, last_refreshed_at, last_error, created_at, updated_at)
+		VALUES(?, ?, ?, ?, ?, ?, ?, '', ?, ?)
 		ON CONFLICT(email) DO UPDATE SET
 			access = excluded.access,
 			refresh = excluded.refresh,
 			access_expiration = excluded.access_expiration,
+			refresh_expiration = excluded.refresh_expiration,
+			status = excluded.status,
+			last_refreshed_at = excluded.last_refreshed_at,
+			last_error = excluded.last_error,
 			updated_at = excluded.updated_at;
 	`, sqliteTableName)
 
@@ -84,17 +153,187 @@ func (repo *SqliteUserSessionRepository) Save(
 		us.AccessToken,
 		us.RefreshToken,
 		sqlite.ToDbTime(us.AccessExpiration),
+		sqlite.ToNullDbTime(us.RefreshExpiration),
+		models.SessionActive,
+		sqlite.ToNullDbTime(us.LastRefreshedAt),
+		sqlite.ToDbTime(time.Now()),
+		sqlite.ToDbTime(time.Now()),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SaveRotated is a compare-and-swap by refresh token,
+// so older pair can never overwrite the newer one.
+func (repo *SqliteUserSessionRepository) SaveRotated(
+	ctx context.Context,
+	previousRefresh string,
+	us models.DtfUserSession,
+) error {
+	queryStr := fmt.Sprintf(`
+		UPDATE %s SET
+			access = ?,
+			refresh = ?,
+			access_expiration = ?,
+			refresh_expiration = ?,
+			status = ?,
+			last_refreshed_at = ?,
+			last_error = '',
+			updated_at = ?
+		WHERE email = ? AND refresh = ?;
+	`, sqliteTableName)
+
+	now := time.Now()
+	result, err := repo.dbProvider.Ext(ctx).ExecContext(
+		ctx,
+		queryStr,
+		us.AccessToken,
+		us.RefreshToken,
+		sqlite.ToDbTime(us.AccessExpiration),
+		sqlite.ToNullDbTime(us.RefreshExpiration),
+		models.SessionActive,
+		sqlite.ToDbTime(now),
+		sqlite.ToDbTime(now),
+		us.Email,
+		previousRefresh,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w: %s", domain.ErrSessionConflict, us.Email)
+	}
+
+	return nil
+}
+
+func (repo *SqliteUserSessionRepository) MarkNeedsRelogin(
+	ctx context.Context,
+	email string,
+	reason string,
+) error {
+	queryStr := fmt.Sprintf(`
+		UPDATE %s SET
+			status = ?,
+			last_error = ?,
+			updated_at = ?
+		WHERE email = ?;
+	`, sqliteTableName)
+
+	result, err := repo.dbProvider.Ext(ctx).ExecContext(
+		ctx,
+		queryStr,
+		models.SessionNeedsRelogin,
+		reason,
 		sqlite.ToDbTime(time.Now()),
+		email,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrUserSessionNotFound
+	}
+
+	return nil
+}
+
+// LinkTelegram links session to existing telegram subscriber
+func (repo *SqliteUserSessionRepository) LinkTelegram(
+	ctx context.Context,
+	email string,
+	telegramId int64,
+) error {
+	var subscriberId int64
+	err := repo.dbProvider.Ext(ctx).QueryRowContext(
+		ctx,
+		fmt.Sprintf(`SELECT id FROM %s WHERE telegram_id = ?;`, dbTableName),
+		telegramId,
+	).Scan(&subscriberId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrTelegramUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	queryStr := fmt.Sprintf(`
+		UPDATE %s SET
+			telegram_subscriber_id = ?,
+			updated_at = ?
+		WHERE email = ?;
+	`, sqliteTableName)
+
+	result, err := repo.dbProvider.Ext(ctx).ExecContext(
+		ctx,
+		queryStr,
+		subscriberId,
 		sqlite.ToDbTime(time.Now()),
+		email,
 	)
+	if err != nil {
+		return err
+	}
 
+	affected, err := result.RowsAffected()
 	if err != nil {
 		return err
 	}
+	if affected == 0 {
+		return domain.ErrUserSessionNotFound
+	}
 
 	return nil
 }
 
+// SetAutoParticipate switches every session linked to telegram user
+func (repo *SqliteUserSessionRepository) SetAutoParticipate(
+	ctx context.Context,
+	telegramId int64,
+	enabled bool,
+) (int, error) {
+	queryStr := fmt.Sprintf(`
+		UPDATE %s SET
+			auto_participate = ?,
+			updated_at = ?
+		WHERE telegram_subscriber_id = (
+			SELECT id FROM telegram_subscribers WHERE telegram_id = ?
+		);
+	`, sqliteTableName)
+
+	result, err := repo.dbProvider.Ext(ctx).ExecContext(
+		ctx,
+		queryStr,
+		enabled,
+		sqlite.ToDbTime(time.Now()),
+		telegramId,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(affected), nil
+}
+
 func (repo *SqliteUserSessionRepository) DeleteByEmail(
 	ctx context.Context,
 	email string,
@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"context"
+	"dtf/game_draw/internal/domain"
+	"dtf/game_draw/internal/domain/models"
+	"dtf/game_draw/internal/domain/repositories"
+	"dtf/game_draw/internal/storage"
+	"dtf/game_draw/internal/storage/sqlite"
+	"fmt"
+)
+
+const sourceRulesTableName = "source_rules"
+
+var _ repositories.SourceRuleRepository = (*SqliteSourceRuleRepository)(nil)
+
+type SqliteSourceRuleRepository struct {
+	dbProvider *storage.Provider
+}
+
+func NewSqliteSourceRuleRepository(dbProvider *storage.Provider) *SqliteSourceRuleRepository {
+	return &SqliteSourceRuleRepository{
+		dbProvider: dbProvider,
+	}
+}
+
+func (r *SqliteSourceRuleRepository) GetByOwners(ctx context.Context, owners ...int64) (models.SourceRules, error) {
+	if len(owners) == 0 {
+		return nil, nil
+	}
+
+	args := make([]any, len(owners))
+	for i, owner := range owners {
+		args[i] = owner
+	}
+	query := fmt.Sprintf(`
+		SELECT owner, kind, source_id, mode, created_at
+		FROM %s
+		WHERE owner IN (%s)
+		ORDER BY created_at;
+	`, sourceRulesTableName, placeholders(len(owners)))
+
+	rows, err := r.dbProvider.Ext(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules models.SourceRules
+	for rows.Next() {
+		var rule models.SourceRule
+		var kind, mode, createdAtRaw string
+		if err := rows.Scan(&rule.Owner, &kind, &rule.SourceId, &mode, &createdAtRaw); err != nil {
+			return rules, err
+		}
+		rule.Kind = models.SourceKind(kind)
+		rule.Mode = models.SourceMode(mode)
+		if rule.CreatedAt, err = sqlite.FromDbTime(createdAtRaw); err != nil {
+			return rules, err
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return rules, err
+	}
+
+	return rules, nil
+}
+
+func (r *SqliteSourceRuleRepository) Save(ctx context.Context, rule models.SourceRule) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (owner, kind, source_id, mode, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (owner, kind, source_id) DO UPDATE
+		SET mode = excluded.mode, created_at = excluded.created_at;
+	`, sourceRulesTableName)
+
+	_, err := r.dbProvider.Ext(ctx).ExecContext(
+		ctx,
+		query,
+		rule.Owner,
+		string(rule.Kind),
+		rule.SourceId,
+		string(rule.Mode),
+		sqlite.ToDbTime(rule.CreatedAt),
+	)
+	return err
+}
+
+func (r *SqliteSourceRuleRepository) Delete(
+	ctx context.Context,
+	owner int64,
+	kind models.SourceKind,
+	sourceId int,
+) error {
+	query := fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE owner = ? AND kind = ? AND source_id = ?;
+	`, sourceRulesTableName)
+
+	res, err := r.dbProvider.Ext(ctx).ExecContext(ctx, query, owner, string(kind), sourceId)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrSourceRuleNotFound
+	}
+
+	return nil
+}
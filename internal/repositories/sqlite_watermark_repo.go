@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"dtf/game_draw/internal/domain"
+	"dtf/game_draw/internal/domain/repositories"
+	"dtf/game_draw/internal/storage"
+	"dtf/game_draw/internal/storage/sqlite"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const watermarksTableName = "sync_watermarks"
+
+var _ repositories.WatermarkRepository = (*SqliteWatermarkRepository)(nil)
+
+type SqliteWatermarkRepository struct {
+	dbProvider *storage.Provider
+}
+
+func NewSqliteWatermarkRepository(dbProvider *storage.Provider) *SqliteWatermarkRepository {
+	return &SqliteWatermarkRepository{
+		dbProvider: dbProvider,
+	}
+}
+
+func (r *SqliteWatermarkRepository) Get(ctx context.Context, name string) (time.Time, error) {
+	query := fmt.Sprintf(`
+		SELECT watermark
+		FROM %s
+		WHERE name = ?
+		LIMIT 1;
+	`, watermarksTableName)
+
+	var watermarkRaw string
+	err := r.dbProvider.Ext(ctx).QueryRowContext(ctx, query, name).Scan(&watermarkRaw)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, domain.ErrWatermarkNotFound
+		}
+		return time.Time{}, err
+	}
+
+	return sqlite.FromDbTime(watermarkRaw)
+}
+
+func (r *SqliteWatermarkRepository) Set(ctx context.Context, name string, watermark time.Time) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (name, watermark, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE
+		SET watermark = excluded.watermark, updated_at = excluded.updated_at;
+	`, watermarksTableName)
+
+	_, err := r.dbProvider.Ext(ctx).ExecContext(
+		ctx,
+		query,
+		name,
+		sqlite.ToDbTime(watermark),
+		sqlite.ToDbTime(time.Now()),
+	)
+	return err
+}
@@ -0,0 +1,47 @@
+// Package sqlitetest creates temporary sqlite databases with applied migrations for tests.
+package sqlitetest
+
+import (
+	"database/sql"
+	"dtf/game_draw/internal/storage/sqlite"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// NewDB returns database in test temp dir with every goose migration applied.
+// Database is closed when test ends.
+func NewDB(t testing.TB) *sql.DB {
+	t.Helper()
+
+	db, err := sqlite.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	paths, err := filepath.Glob(filepath.Join(migrationsDir(), "*.sql"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("find migrations: %v", err)
+	}
+	// names start with timestamp, glob result is sorted
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read migration: %v", err)
+		}
+		up, _, _ := strings.Cut(string(content), "-- +goose Down")
+		if _, err := db.Exec(up); err != nil {
+			t.Fatalf("apply migration %s: %v", filepath.Base(path), err)
+		}
+	}
+
+	return db
+}
+
+func migrationsDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "..", "..", "migrations")
+}
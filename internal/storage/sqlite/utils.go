@@ -1,6 +1,9 @@
 package sqlite
 
-import "time"
+import (
+	"database/sql"
+	"time"
+)
 
 func ToDbTime(t time.Time) string {
 	return t.UTC().Format(time.RFC3339)
@@ -14,3 +17,21 @@ func FromDbTime(t string) (time.Time, error) {
 
 	return result, nil
 }
+
+// ToNullDbTime stores zero time as NULL
+func ToNullDbTime(t time.Time) sql.NullString {
+	if t.IsZero() {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: ToDbTime(t), Valid: true}
+}
+
+// FromNullDbTime returns zero time for NULL
+func FromNullDbTime(t sql.NullString) (time.Time, error) {
+	if !t.Valid || t.String == "" {
+		return time.Time{}, nil
+	}
+
+	return FromDbTime(t.String)
+}
@@ -14,7 +14,11 @@ import (
 func NewBot(
 	botToken string,
 	telegramSessionRepo repositories.TelegramSubscribersRepository,
-	activeRafflesUseCase *usecases.GetActiveRafflePostsUseCase,
+	dtfSessionRepo repositories.DtfSessionRepository,
+	openRafflesUseCase *usecases.GetOpenRafflesUseCase,
+	deliverRafflesUseCase *usecases.DeliverRafflesUseCase,
+	sourceRulesUseCase *usecases.SourceRulesUseCase,
+	linkDtfAccountUseCase *usecases.LinkDtfAccountUseCase,
 	telegramAdmins []int64,
 ) (*tele.Bot, error) {
 	startTime := time.Now()
@@ -53,7 +57,16 @@ func NewBot(
 		telegramAdmins,
 	)
 	postHandlers := telegram_handlers.NewTelegramPostHandlers(
-		activeRafflesUseCase,
+		openRafflesUseCase,
+		deliverRafflesUseCase,
+	)
+	participationHandlers := telegram_handlers.NewTelegramParticipationHandlers(
+		dtfSessionRepo,
+		linkDtfAccountUseCase,
+	)
+	sourceHandlers := telegram_handlers.NewTelegramSourceHandlers(
+		sourceRulesUseCase,
+		telegramAdmins,
 	)
 
 	bot.Handle("/start", func(ctx tele.Context) error {
@@ -63,6 +76,12 @@ func NewBot(
 	bot.Handle("/subscribe", authHandlers.Subscribe)
 	bot.Handle("/unsubscribe", authHandlers.Unsubscribe)
 	bot.Handle("/today_raffles", postHandlers.GetTodayRaffles)
+	bot.Handle("/allow", sourceHandlers.Allow)
+	bot.Handle("/deny", sourceHandlers.Deny)
+	bot.Handle("/unlist", sourceHandlers.Unlist)
+	bot.Handle("/lists", sourceHandlers.Lists)
+	bot.Handle("/link_dtf", participationHandlers.LinkDtf)
+	bot.Handle("/auto_participate", participationHandlers.AutoParticipate)
 
 	return bot, nil
 }
@@ -86,7 +105,31 @@ func setCommands(bot *tele.Bot) error {
 		},
 		{
 			Text:        "/today_raffles",
-			Description: "Получить список последних розыгрышей",
+			Description: "Получить список последних розыгрышей, можно с фильтрами: pc, noconsole, keys, trust50...",
+		},
+		{
+			Text:        "/allow",
+			Description: "Показывать розыгрыши автора или подсайта: author|subsite <id или ссылка>",
+		},
+		{
+			Text:        "/deny",
+			Description: "Скрыть розыгрыши автора или подсайта: author|subsite <id или ссылка>",
+		},
+		{
+			Text:        "/unlist",
+			Description: "Удалить правило для автора или подсайта",
+		},
+		{
+			Text:        "/lists",
+			Description: "Показать списки авторов и подсайтов",
+		},
+		{
+			Text:        "/link_dtf",
+			Description: "Привязать аккаунт DTF: <почта> <пароль>, сообщение с паролем удаляется",
+		},
+		{
+			Text:        "/auto_participate",
+			Description: "Автоматически участвовать в розыгрышах привязанными аккаунтами DTF: on|off",
 		},
 	}
 
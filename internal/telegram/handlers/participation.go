@@ -0,0 +1,114 @@
+package telegram_handlers
+
+import (
+	"context"
+	"dtf/game_draw/internal/domain"
+	"dtf/game_draw/internal/domain/repositories"
+	telegram_utils "dtf/game_draw/internal/telegram/utils"
+	"dtf/game_draw/internal/usecases"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+const (
+	autoParticipateHelpText = "⚠️ Формат: /auto_participate on|off"
+	linkDtfHelpText         = "⚠️ Формат: /link_dtf <почта> <пароль>"
+)
+
+// telebot doesn't pass context, handlers limit their requests themselves
+const participationRequestTimeout = 30 * time.Second
+
+type TelegramParticipationHandlers struct {
+	sessionRepo           repositories.DtfSessionRepository
+	linkDtfAccountUseCase *usecases.LinkDtfAccountUseCase
+}
+
+func NewTelegramParticipationHandlers(
+	sessionRepo repositories.DtfSessionRepository,
+	linkDtfAccountUseCase *usecases.LinkDtfAccountUseCase,
+) *TelegramParticipationHandlers {
+	return &TelegramParticipationHandlers{
+		sessionRepo:           sessionRepo,
+		linkDtfAccountUseCase: linkDtfAccountUseCase,
+	}
+}
+
+// LinkDtf logs in to DTF account and links it to the sender: "/link_dtf user@mail.ru password".
+// Message with password is deleted right away.
+func (h *TelegramParticipationHandlers) LinkDtf(ctx tele.Context) error {
+	user := ctx.Sender()
+	if user == nil {
+		return ctx.Send(telegram_utils.ErrTextUserNotFound)
+	}
+
+	if err := ctx.Delete(); err != nil {
+		slog.Warn("message with dtf password wasn't deleted", "error", err, "telegram_id", user.ID)
+	}
+
+	args := ctx.Args()
+	if len(args) != 2 {
+		return ctx.Send(linkDtfHelpText)
+	}
+	email, password := args[0], args[1]
+
+	reqCtx, cancel := context.WithTimeout(context.Background(), participationRequestTimeout)
+	defer cancel()
+
+	err := h.linkDtfAccountUseCase.Execute(reqCtx, user.ID, email, password)
+	switch {
+	case err == nil:
+		return ctx.Send(fmt.Sprintf("✅ Аккаунт %s привязан. Включить автоучастие: /auto_participate on", email))
+	case errors.Is(err, domain.ErrInvalidCredentials):
+		return ctx.Send("⚠️ Неверная почта или пароль.")
+	case errors.Is(err, domain.ErrTelegramUserNotFound):
+		return ctx.Send("⚠️ Сначала подпишись: /subscribe")
+	default:
+		slog.Error("dtf account linking failed", "error", err, "telegram_id", user.ID, "email", email)
+		return ctx.Send(telegram_utils.ErrTextUnknown)
+	}
+}
+
+// AutoParticipate switches automatic participation of linked DTF accounts: "/auto_participate on"
+func (h *TelegramParticipationHandlers) AutoParticipate(ctx tele.Context) error {
+	user := ctx.Sender()
+	if user == nil {
+		return ctx.Send(telegram_utils.ErrTextUserNotFound)
+	}
+
+	args := ctx.Args()
+	if len(args) != 1 {
+		return ctx.Send(autoParticipateHelpText)
+	}
+
+	var enabled bool
+	switch strings.ToLower(args[0]) {
+	case "on":
+		enabled = true
+	case "off":
+		enabled = false
+	default:
+		return ctx.Send(autoParticipateHelpText)
+	}
+
+	reqCtx, cancel := context.WithTimeout(context.Background(), participationRequestTimeout)
+	defer cancel()
+
+	updated, err := h.sessionRepo.SetAutoParticipate(reqCtx, user.ID, enabled)
+	if err != nil {
+		slog.Error("auto participation switch failed", "error", err, "telegram_id", user.ID)
+		return ctx.Send(telegram_utils.ErrTextUnknown)
+	}
+	if updated == 0 {
+		return ctx.Send("⚠️ К тебе не привязан ни один аккаунт DTF. Привязать: /link_dtf <почта> <пароль>")
+	}
+
+	if enabled {
+		return ctx.Send(fmt.Sprintf("✅ Готово! Аккаунтов с автоучастием: %d. Пришлю отчёт, когда поучаствую.", updated))
+	}
+	return ctx.Send("✅ Готово! Автоучастие выключено.")
+}
@@ -2,38 +2,55 @@ package telegram_handlers
 
 import (
 	"context"
+	"dtf/game_draw/internal/domain/models"
 	telegram_utils "dtf/game_draw/internal/telegram/utils"
 	"dtf/game_draw/internal/usecases"
 	"errors"
+	"fmt"
 	"log/slog"
-	"time"
+	"strings"
 
 	"gopkg.in/telebot.v4"
 )
 
 type TelegramPostHandlers struct {
-	activeRafflesUseCase *usecases.GetActiveRafflePostsUseCase
+	openRafflesUseCase    *usecases.GetOpenRafflesUseCase
+	deliverRafflesUseCase *usecases.DeliverRafflesUseCase
 }
 
 func NewTelegramPostHandlers(
-	activeRafflesUseCase *usecases.GetActiveRafflePostsUseCase,
+	openRafflesUseCase *usecases.GetOpenRafflesUseCase,
+	deliverRafflesUseCase *usecases.DeliverRafflesUseCase,
 ) *TelegramPostHandlers {
 	return &TelegramPostHandlers{
-		activeRafflesUseCase: activeRafflesUseCase,
+		openRafflesUseCase:    openRafflesUseCase,
+		deliverRafflesUseCase: deliverRafflesUseCase,
 	}
 }
 
 func (h *TelegramPostHandlers) GetTodayRaffles(ctx telebot.Context) error {
+	user := ctx.Sender()
+	if user == nil {
+		return ctx.Send(telegram_utils.ErrTextUserNotFound)
+	}
+
 	// telebot doesn't provide context.Context
 	// hope in the future it will be availble
-	posts, err := h.activeRafflesUseCase.Execute(context.TODO(), time.Now().AddDate(0, 0, -1))
+	filter, unknown := telegram_utils.ParseRaffleFilter(ctx.Args())
+	if len(unknown) > 0 {
+		return ctx.Send(fmt.Sprintf("Не понял фильтр: %s\n%s", strings.Join(unknown, ", "), telegram_utils.FilterHelpText))
+	}
+
+	// answering from local catalog, scheduled job keeps it fresh
+	posts, err := h.openRafflesUseCase.Execute(context.TODO(), user.ID)
 	if err != nil {
 		slog.Error("Get active raffles telegram error", "error", err)
 		return ctx.Send("Прости друг, не смог достать новости. Попробуй позже.")
 	}
+	posts = filter.Apply(posts)
 
 	if len(posts) == 0 {
-		return ctx.Send("За сегодня не было розыгрышей")
+		return ctx.Send("Сейчас нет активных розыгрышей")
 	}
 
 	response := telegram_utils.ManyPostsToTelegramText(posts, false)
@@ -45,6 +62,7 @@ func (h *TelegramPostHandlers) GetTodayRaffles(ctx telebot.Context) error {
 	}
 	if err = ctx.Send(response, telebot.NoPreview); err == nil {
 		// happy path ends
+		h.markDelivered(user.ID, posts)
 		return nil
 	}
 
@@ -54,6 +72,7 @@ func (h *TelegramPostHandlers) GetTodayRaffles(ctx telebot.Context) error {
 		response = telegram_utils.ManyPostsToTelegramText(posts, true)
 		if err := ctx.Send(response); err == nil {
 			// ok, everyone happy exiting...
+			h.markDelivered(user.ID, posts)
 			return nil
 		}
 	}
@@ -62,3 +81,10 @@ func (h *TelegramPostHandlers) GetTodayRaffles(ctx telebot.Context) error {
 	return ctx.Send("Ошибка. Что-то пошло не так.")
 
 }
+
+// markDelivered keeps shown raffles out of the next digest
+func (h *TelegramPostHandlers) markDelivered(telegramId int64, posts []models.Raffle) {
+	if err := h.deliverRafflesUseCase.MarkDelivered(context.TODO(), telegramId, posts); err != nil {
+		slog.Error("Cant mark raffles as delivered", "error", err, "telegram_id", telegramId)
+	}
+}
@@ -0,0 +1,117 @@
+package telegram_handlers
+
+import (
+	"context"
+	"dtf/game_draw/internal/domain"
+	"dtf/game_draw/internal/domain/models"
+	telegram_utils "dtf/game_draw/internal/telegram/utils"
+	"dtf/game_draw/internal/usecases"
+	"errors"
+	"html"
+	"log/slog"
+	"slices"
+
+	tele "gopkg.in/telebot.v4"
+)
+
+type TelegramSourceHandlers struct {
+	sourceRulesUseCase *usecases.SourceRulesUseCase
+	telegramAdmins     []int64
+}
+
+func NewTelegramSourceHandlers(
+	sourceRulesUseCase *usecases.SourceRulesUseCase,
+	telegramAdmins []int64,
+) *TelegramSourceHandlers {
+	return &TelegramSourceHandlers{
+		sourceRulesUseCase: sourceRulesUseCase,
+		telegramAdmins:     telegramAdmins,
+	}
+}
+
+func (h *TelegramSourceHandlers) Allow(ctx tele.Context) error {
+	return h.setRule(ctx, models.SourceAllow)
+}
+
+func (h *TelegramSourceHandlers) Deny(ctx tele.Context) error {
+	return h.setRule(ctx, models.SourceDeny)
+}
+
+func (h *TelegramSourceHandlers) Unlist(ctx tele.Context) error {
+	user := ctx.Sender()
+	if user == nil {
+		return ctx.Send(telegram_utils.ErrTextUserNotFound)
+	}
+	args, owner, problem := h.parseArgs(ctx.Args(), user.ID)
+	if problem != "" {
+		return ctx.Send(problem)
+	}
+
+	err := h.sourceRulesUseCase.Remove(context.TODO(), owner, args.Kind, args.SourceId)
+	if err != nil {
+		if errors.Is(err, domain.ErrSourceRuleNotFound) {
+			return ctx.Send("⚠️ Такого правила нет.")
+		}
+		slog.Error("source rule removal failed", "error", err, "telegram_id", user.ID)
+		return ctx.Send(telegram_utils.ErrTextUnknown)
+	}
+
+	return ctx.Send("✅ Правило удалено.")
+}
+
+func (h *TelegramSourceHandlers) Lists(ctx tele.Context) error {
+	user := ctx.Sender()
+	if user == nil {
+		return ctx.Send(telegram_utils.ErrTextUserNotFound)
+	}
+
+	rules, err := h.sourceRulesUseCase.Rules(context.TODO(), user.ID)
+	if err != nil {
+		slog.Error("source rules loading failed", "error", err, "telegram_id", user.ID)
+		return ctx.Send(telegram_utils.ErrTextUnknown)
+	}
+
+	return ctx.Send(telegram_utils.SourceRulesToTelegramText(rules), tele.NoPreview)
+}
+
+func (h *TelegramSourceHandlers) setRule(ctx tele.Context, mode models.SourceMode) error {
+	user := ctx.Sender()
+	if user == nil {
+		return ctx.Send(telegram_utils.ErrTextUserNotFound)
+	}
+	args, owner, problem := h.parseArgs(ctx.Args(), user.ID)
+	if problem != "" {
+		return ctx.Send(problem)
+	}
+
+	err := h.sourceRulesUseCase.Set(context.TODO(), owner, args.Kind, args.SourceId, mode)
+	if err != nil {
+		slog.Error("source rule saving failed", "error", err, "telegram_id", user.ID)
+		return ctx.Send(telegram_utils.ErrTextUnknown)
+	}
+
+	if mode == models.SourceDeny {
+		return ctx.Send("✅ Готово! Розыгрыши отсюда больше не покажу.")
+	}
+	return ctx.Send("✅ Готово! Розыгрыши отсюда буду показывать.")
+}
+
+// parseArgs returns problem to tell user if arguments are wrong
+// or global rule is changed not by admin
+func (h *TelegramSourceHandlers) parseArgs(
+	rawArgs []string,
+	telegramId int64,
+) (telegram_utils.SourceRuleArgs, int64, string) {
+	args, err := telegram_utils.ParseSourceRuleArgs(rawArgs)
+	if err != nil {
+		return args, 0, "⚠️ Не понял.\n\n" + html.EscapeString(telegram_utils.SourceRuleHelpText)
+	}
+
+	if !args.Global {
+		return args, telegramId, ""
+	}
+	if !slices.Contains(h.telegramAdmins, telegramId) {
+		return args, 0, "⚠️ Общие правила могут менять только админы."
+	}
+	return args, models.GlobalRulesOwner, ""
+}
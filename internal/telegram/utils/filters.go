@@ -0,0 +1,62 @@
+package telegram_utils
+
+import (
+	"dtf/game_draw/internal/domain/models"
+	"strconv"
+	"strings"
+)
+
+// command arguments, e.g. "/today_raffles pc noconsole"
+var filterPlatforms = map[string]models.Platform{
+	"pc":       models.PlatformPC,
+	"пк":       models.PlatformPC,
+	"ps":       models.PlatformPlayStation,
+	"xbox":     models.PlatformXbox,
+	"switch":   models.PlatformNintendo,
+	"nintendo": models.PlatformNintendo,
+	"mobile":   models.PlatformMobile,
+}
+
+var filterKinds = map[string]models.PrizeKind{
+	"keys":  models.PrizeKey,
+	"games": models.PrizeGame,
+	"cards": models.PrizeGiftCard,
+	"subs":  models.PrizeSubscription,
+	"merch": models.PrizeMerch,
+	"hw":    models.PrizeHardware,
+}
+
+// minimal organizer trust, e.g. "trust50"
+const trustFilterPrefix = "trust"
+
+const FilterHelpText = "Фильтры: pc, ps, xbox, switch, mobile, keys, games, cards, subs, merch, hw, noconsole, trust50"
+
+// ParseRaffleFilter builds filter from command arguments, unknown ones are returned
+func ParseRaffleFilter(args []string) (models.RaffleFilter, []string) {
+	var filter models.RaffleFilter
+	var unknown []string
+	for _, arg := range args {
+		arg = strings.ToLower(strings.TrimSpace(arg))
+		if platform, ok := filterPlatforms[arg]; ok {
+			filter.Platforms = append(filter.Platforms, platform)
+			continue
+		}
+		if kind, ok := filterKinds[arg]; ok {
+			filter.Kinds = append(filter.Kinds, kind)
+			continue
+		}
+		if arg == "noconsole" {
+			filter.SkipConsoleOnly = true
+			continue
+		}
+		if trust, ok := strings.CutPrefix(arg, trustFilterPrefix); ok {
+			if n, err := strconv.Atoi(trust); err == nil && n >= 0 && n <= 100 {
+				filter.MinTrust = n
+				continue
+			}
+		}
+		unknown = append(unknown, arg)
+	}
+
+	return filter, unknown
+}
@@ -3,15 +3,46 @@ package telegram_utils
 import (
 	"dtf/game_draw/internal/domain/models"
 	"fmt"
+	"html"
+	"slices"
 	"strings"
+	"time"
 )
 
-func PostToTelegramText(post models.Post, short bool) string {
+// deadlines are shown in Moscow time, as DTF users expect
+var moscow = time.FixedZone("MSK", 3*60*60)
+
+func PostToTelegramText(raffle models.Raffle, short bool) string {
+	post := raffle.Post
 	sb := strings.Builder{}
 
 	// header
 	_, _ = fmt.Fprintf(&sb, "🎁 <b>%s</b>\n", post.Title)
 
+	if raffle.Risk.IsRisky() {
+		_, _ = fmt.Fprintf(&sb, "%s\n", riskText(raffle.Risk))
+	}
+
+	if meta := postMetaText(post); meta != "" {
+		_, _ = fmt.Fprintf(&sb, "%s\n", meta)
+	}
+
+	if raffle.Author.Id != 0 {
+		_, _ = fmt.Fprintf(&sb, "%s\n", reputationText(raffle.Organizer))
+	}
+
+	if raffle.EndsAt.IsKnown() {
+		_, _ = fmt.Fprintf(&sb, "%s\n", deadlineText(raffle.EndsAt))
+	}
+
+	if prizes := prizesText(raffle.Prizes); prizes != "" {
+		_, _ = fmt.Fprintf(&sb, "%s\n", prizes)
+	}
+
+	if conditions := conditionsText(raffle.Conditions); conditions != "" {
+		_, _ = fmt.Fprintf(&sb, "%s\n", conditions)
+	}
+
 	if post.Text != "" && !short {
 		_, _ = fmt.Fprintf(&sb, "<blockquote expandable>%s</blockquote>", post.Text)
 	}
@@ -21,13 +52,13 @@ func PostToTelegramText(post models.Post, short bool) string {
 	return sb.String()
 }
 
-func ManyPostsToTelegramText(posts []models.Post, short bool) string {
+func ManyPostsToTelegramText(raffles []models.Raffle, short bool) string {
 	builder := strings.Builder{}
-	for i, post := range posts {
+	for i, raffle := range raffles {
 		if i > 0 {
 			builder.WriteString("\n✦ ✦ ✦\n")
 		}
-		text := PostToTelegramText(post, short)
+		text := PostToTelegramText(raffle, short)
 		builder.WriteString(text)
 	}
 	if short {
@@ -35,3 +66,226 @@ func ManyPostsToTelegramText(posts []models.Post, short bool) string {
 	}
 	return builder.String()
 }
+
+// postMetaText renders author and popularity line, e.g. "👤 Author · ❤️ 10 · 💬 5"
+func postMetaText(post models.Post) string {
+	var parts []string
+	if post.Author.Name != "" {
+		parts = append(parts, "👤 "+html.EscapeString(post.Author.Name))
+	}
+	if post.Subsite.Name != "" && post.Subsite.Id != post.Author.Id {
+		parts = append(parts, "📁 "+html.EscapeString(post.Subsite.Name))
+	}
+	if post.Likes > 0 {
+		parts = append(parts, fmt.Sprintf("❤️ %d", post.Likes))
+	}
+	if post.Comments > 0 {
+		parts = append(parts, fmt.Sprintf("💬 %d", post.Comments))
+	}
+	if post.Views > 0 {
+		parts = append(parts, fmt.Sprintf("👁 %d", post.Views))
+	}
+
+	return strings.Join(parts, " · ")
+}
+
+// riskText renders "⚠️ осторожно: сокращённая ссылка bit.ly, просят оплату"
+func riskText(risk models.RaffleRisk) string {
+	label := "⚠️ осторожно"
+	if risk.Level == models.RiskHigh {
+		label = "⛔️ похоже на скам"
+	}
+	return fmt.Sprintf("%s: %s", label, html.EscapeString(strings.Join(risk.Reasons, ", ")))
+}
+
+// reputationText renders "🛡 доверие 86/100 · итоги 5 из 6 · через ~2 дн."
+func reputationText(reputation models.OrganizerReputation) string {
+	if !reputation.IsKnown() {
+		return "🛡 организатор без истории"
+	}
+
+	stats := reputation.Stats
+	parts := []string{
+		fmt.Sprintf("🛡 доверие %d/100", reputation.Score),
+		fmt.Sprintf("итоги %d из %d", stats.WithResults, stats.Finished),
+	}
+	if stats.WithResults > 0 {
+		parts = append(parts, fmt.Sprintf("через ~%d дн.", int(stats.AvgResultsDelay.Round(24*time.Hour).Hours()/24)))
+	}
+
+	return strings.Join(parts, " · ")
+}
+
+// deadlineText renders "⏳ до 25.10 18:00 МСК", guessed deadlines are marked with "~"
+func deadlineText(deadline models.Deadline) string {
+	layout := "02.01 15:04 МСК"
+	if deadline.DateOnly {
+		layout = "02.01"
+	}
+	approx := ""
+	if deadline.Confidence == models.DeadlineLow {
+		approx = "~"
+	}
+
+	return fmt.Sprintf("⏳ до %s%s", approx, deadline.Time.In(moscow).Format(layout))
+}
+
+// conditionsText renders what to do, e.g. "📋 ❤️ лайк · 💬 «Участвую» · 🔗 steam"
+func conditionsText(conditions models.RaffleConditions) string {
+	var parts []string
+	if conditions.Like {
+		parts = append(parts, "❤️ лайк")
+	}
+	if conditions.Comment {
+		if conditions.CommentText != "" {
+			parts = append(parts, "💬 «"+html.EscapeString(conditions.CommentText)+"»")
+		} else {
+			parts = append(parts, "💬 коммент")
+		}
+	}
+	if conditions.SubscribeAuthor {
+		parts = append(parts, "👤 подписка")
+	}
+	if conditions.Repost {
+		parts = append(parts, "🔁 репост")
+	}
+	if len(conditions.External) > 0 {
+		var services []string
+		for _, action := range conditions.External {
+			if !slices.Contains(services, string(action.Service)) {
+				services = append(services, string(action.Service))
+			}
+		}
+		parts = append(parts, "🔗 "+strings.Join(services, ", "))
+	}
+	if conditions.MinAccountDays > 0 {
+		parts = append(parts, fmt.Sprintf("🕰 аккаунт от %d дн.", conditions.MinAccountDays))
+	}
+	if conditions.MinKarma > 0 {
+		parts = append(parts, fmt.Sprintf("⭐ карма от %d", conditions.MinKarma))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+
+	return "📋 " + strings.Join(parts, " · ")
+}
+
+var prizeKindTags = map[models.PrizeKind]string{
+	models.PrizeGame:         "#игра",
+	models.PrizeKey:          "#ключ",
+	models.PrizeGiftCard:     "#гифткарта",
+	models.PrizeSubscription: "#подписка",
+	models.PrizeMerch:        "#мерч",
+	models.PrizeHardware:     "#железо",
+}
+
+// prizesText renders tags, e.g. "🏷 #pc #ключ · 🎮 Hades · 🏆 3"
+func prizesText(prizes models.RafflePrizes) string {
+	var tags []string
+	for _, platform := range prizes.Platforms {
+		tags = append(tags, "#"+string(platform))
+	}
+	for _, kind := range prizes.Kinds {
+		tags = append(tags, prizeKindTags[kind])
+	}
+
+	var parts []string
+	if len(tags) > 0 {
+		parts = append(parts, "🏷 "+strings.Join(tags, " "))
+	}
+	if len(prizes.Games) > 0 {
+		parts = append(parts, "🎮 "+html.EscapeString(strings.Join(prizes.Games, ", ")))
+	}
+	if prizes.Winners > 0 {
+		parts = append(parts, fmt.Sprintf("🏆 %d", prizes.Winners))
+	}
+
+	return strings.Join(parts, " · ")
+}
+
+// ResultsToTelegramText tells that raffles results are out
+func ResultsToTelegramText(raffles []models.Raffle) string {
+	sb := strings.Builder{}
+	for i, raffle := range raffles {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		_, _ = fmt.Fprintf(
+			&sb,
+			"🏁 Итоги розыгрыша <b>%s</b>\n%s\n",
+			html.EscapeString(raffle.Title),
+			raffle.Results.Uri,
+		)
+	}
+	return sb.String()
+}
+
+// WinToTelegramText tells that our account won a raffle
+func WinToTelegramText(win models.RaffleWin) string {
+	format := "🎉 Аккаунт <b>%s</b> (%s) победил в розыгрыше <b>%s</b>\n<blockquote>%s</blockquote>\n%s"
+	if win.NameOnly {
+		format = "🤔 Похоже, аккаунт <b>%s</b> (%s) победил в розыгрыше <b>%s</b>, " +
+			"но найден только ник без ссылки, проверь сам\n<blockquote>%s</blockquote>\n%s"
+	}
+	return fmt.Sprintf(
+		format,
+		html.EscapeString(win.DtfUser.Name),
+		html.EscapeString(win.Email),
+		html.EscapeString(win.RaffleTitle),
+		html.EscapeString(win.Fragment),
+		win.Uri,
+	)
+}
+
+// ParticipationReportToTelegramText tells what was done on behalf of account
+func ParticipationReportToTelegramText(report models.ParticipationReport) string {
+	sb := strings.Builder{}
+	_, _ = fmt.Fprintf(&sb, "🤖 Автоучастие <b>%s</b>\n", html.EscapeString(report.Email))
+
+	if report.Error != "" {
+		_, _ = fmt.Fprintf(&sb, "\n⚠️ Не получилось войти: %s\n", html.EscapeString(report.Error))
+	}
+
+	if len(report.Joined) > 0 {
+		_, _ = fmt.Fprintf(&sb, "\n✅ Участвую (%d):\n", len(report.Joined))
+		for _, p := range report.Joined {
+			_, _ = fmt.Fprintf(&sb, "• %s\n", participationLine(p))
+			if len(p.Participation.ManualActions) > 0 {
+				_, _ = fmt.Fprintf(
+					&sb,
+					"  👉 сделай сам: %s\n",
+					html.EscapeString(strings.Join(p.Participation.ManualActions, ", ")),
+				)
+			}
+		}
+	}
+
+	if len(report.Skipped) > 0 {
+		_, _ = fmt.Fprintf(&sb, "\n⛔️ Пропущено (%d):\n", len(report.Skipped))
+		for _, p := range report.Skipped {
+			_, _ = fmt.Fprintf(&sb, "• %s — %s\n", participationLine(p), html.EscapeString(p.Reason))
+		}
+	}
+
+	if len(report.Failed) > 0 {
+		_, _ = fmt.Fprintf(&sb, "\n❌ Ошибки (%d):\n", len(report.Failed))
+		for _, p := range report.Failed {
+			_, _ = fmt.Fprintf(&sb, "• %s — %s\n", participationLine(p), html.EscapeString(p.Reason))
+		}
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+// participationLine renders "<a>title</a> ❤️ 💬 «Участвую»"
+func participationLine(p models.RaffleParticipation) string {
+	line := fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(p.Raffle.Uri), html.EscapeString(p.Raffle.Title))
+	if p.Participation.Liked {
+		line += " ❤️"
+	}
+	if p.Participation.Commented {
+		line += " 💬 «" + html.EscapeString(p.Participation.CommentText) + "»"
+	}
+	return line
+}
@@ -0,0 +1,110 @@
+package telegram_utils
+
+import (
+	"dtf/game_draw/internal/domain/models"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const SourceRuleHelpText = "Формат: [global] author|subsite <id или ссылка>\n" +
+	"Например: /deny author https://dtf.ru/u/123-name\n" +
+	"global — правило для всех, только для админов"
+
+var sourceKinds = map[string]models.SourceKind{
+	"author":  models.SourceAuthor,
+	"автор":   models.SourceAuthor,
+	"subsite": models.SourceSubsite,
+	"подсайт": models.SourceSubsite,
+}
+
+// profile and subsite links: dtf.ru/u/123-name, dtf.ru/s/123-name, dtf.ru/id123
+var sourceLinkRe = regexp.MustCompile(`dtf\.ru/(?:u/|s/|id)(\d+)`)
+
+var ErrBadSourceRuleArgs = errors.New("bad source rule arguments")
+
+// SourceRuleArgs are parsed arguments of /allow, /deny and /unlist commands
+type SourceRuleArgs struct {
+	Global   bool
+	Kind     models.SourceKind
+	SourceId int
+}
+
+// ParseSourceRuleArgs parses "[global] author|subsite <id or link>"
+func ParseSourceRuleArgs(args []string) (SourceRuleArgs, error) {
+	var parsed SourceRuleArgs
+	if len(args) > 0 && strings.EqualFold(args[0], "global") {
+		parsed.Global = true
+		args = args[1:]
+	}
+	if len(args) != 2 {
+		return parsed, ErrBadSourceRuleArgs
+	}
+
+	kind, ok := sourceKinds[strings.ToLower(args[0])]
+	if !ok {
+		return parsed, ErrBadSourceRuleArgs
+	}
+	parsed.Kind = kind
+
+	id, err := strconv.Atoi(args[1])
+	if err != nil {
+		m := sourceLinkRe.FindStringSubmatch(args[1])
+		if m == nil {
+			return parsed, ErrBadSourceRuleArgs
+		}
+		id, _ = strconv.Atoi(m[1])
+	}
+	if id <= 0 {
+		return parsed, ErrBadSourceRuleArgs
+	}
+	parsed.SourceId = id
+
+	return parsed, nil
+}
+
+// SourceRulesToTelegramText lists global and personal rules separately
+func SourceRulesToTelegramText(rules models.SourceRules) string {
+	var global, personal []string
+	for _, rule := range rules {
+		line := sourceRuleLine(rule)
+		if rule.IsGlobal() {
+			global = append(global, line)
+			continue
+		}
+		personal = append(personal, line)
+	}
+
+	if len(global)+len(personal) == 0 {
+		return "Списков пока нет\n\n" + html.EscapeString(SourceRuleHelpText)
+	}
+
+	var b strings.Builder
+	if len(personal) > 0 {
+		b.WriteString("<b>Твои правила</b>\n")
+		b.WriteString(strings.Join(personal, "\n"))
+		b.WriteString("\n\n")
+	}
+	if len(global) > 0 {
+		b.WriteString("<b>Общие правила</b>\n")
+		b.WriteString(strings.Join(global, "\n"))
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func sourceRuleLine(rule models.SourceRule) string {
+	mark := "✅"
+	if rule.Mode == models.SourceDeny {
+		mark = "🚫"
+	}
+	link := fmt.Sprintf("https://dtf.ru/u/%d", rule.SourceId)
+	kind := "автор"
+	if rule.Kind == models.SourceSubsite {
+		link = fmt.Sprintf("https://dtf.ru/s/%d", rule.SourceId)
+		kind = "подсайт"
+	}
+	return fmt.Sprintf("%s %s %d %s", mark, kind, rule.SourceId, link)
+}
@@ -0,0 +1,21 @@
+package usecases
+
+import (
+	"dtf/game_draw/internal/domain/models"
+	"dtf/game_draw/internal/domain/raffle"
+)
+
+// analyzeRaffles extracts everything we can from raffle text.
+// Already known deadline is kept, catalog stores it.
+func analyzeRaffles(raffles []models.Raffle) []models.Raffle {
+	for i := range raffles {
+		post := raffles[i].Post
+		if !raffles[i].EndsAt.IsKnown() {
+			raffles[i].EndsAt = raffle.ExtractDeadline(post)
+		}
+		raffles[i].Conditions = raffle.ExtractConditions(post)
+		raffles[i].Prizes = raffle.ExtractPrizes(post)
+		raffles[i].Risk = raffle.AssessRisk(post)
+	}
+	return raffles
+}
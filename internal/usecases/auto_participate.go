@@ -0,0 +1,238 @@
+package usecases
+
+import (
+	"context"
+	"dtf/game_draw/internal/domain"
+	"dtf/game_draw/internal/domain/managers"
+	"dtf/game_draw/internal/domain/models"
+	"dtf/game_draw/internal/domain/repositories"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	// DTF doesn't like bursts of comments from one account,
+	// the rest of raffles waits for the next run
+	maxParticipationsPerRun = 10
+	// failed participation is reported and not tried anymore after that
+	maxParticipationAttempts = 3
+)
+
+// AutoParticipateUseCase takes part in open raffles
+// on behalf of every opted-in account
+type AutoParticipateUseCase struct {
+	sessionRepo       repositories.DtfSessionRepository
+	authRepo          repositories.AuthRepository
+	raffleRepo        repositories.RaffleRepository
+	postRepo          repositories.PostRepository
+	participationRepo repositories.RaffleParticipationRepository
+	sourceRuleRepo    repositories.SourceRuleRepository
+	userManager       managers.UserManager
+	likeAndPost       *LikeAndPostToRafflePostUseCase
+}
+
+func NewAutoParticipateUseCase(
+	sessionRepo repositories.DtfSessionRepository,
+	authRepo repositories.AuthRepository,
+	raffleRepo repositories.RaffleRepository,
+	postRepo repositories.PostRepository,
+	participationRepo repositories.RaffleParticipationRepository,
+	sourceRuleRepo repositories.SourceRuleRepository,
+	userManager managers.UserManager,
+	likeAndPost *LikeAndPostToRafflePostUseCase,
+) *AutoParticipateUseCase {
+	return &AutoParticipateUseCase{
+		sessionRepo:       sessionRepo,
+		authRepo:          authRepo,
+		raffleRepo:        raffleRepo,
+		postRepo:          postRepo,
+		participationRepo: participationRepo,
+		sourceRuleRepo:    sourceRuleRepo,
+		userManager:       userManager,
+		likeAndPost:       likeAndPost,
+	}
+}
+
+// Execute participates in open raffles every account hasn't seen yet
+// and returns non empty reports
+func (uc *AutoParticipateUseCase) Execute(ctx context.Context) ([]models.ParticipationReport, error) {
+	sessions, err := uc.sessionRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var participants []models.DtfUserSession
+	for _, session := range sessions {
+		if session.AutoParticipate && !session.NeedsRelogin() {
+			participants = append(participants, session)
+		}
+	}
+	if len(participants) == 0 {
+		return nil, nil
+	}
+
+	raffles, err := uc.raffleRepo.GetByStates(
+		ctx,
+		models.RaffleStateNew,
+		models.RaffleStateActive,
+		models.RaffleStateEndingSoon,
+	)
+	if err != nil {
+		return nil, err
+	}
+	raffles = analyzeRaffles(raffles)
+
+	var reports []models.ParticipationReport
+	for _, session := range participants {
+		report, err := uc.participate(ctx, session, raffles)
+		if err != nil {
+			return reports, err
+		}
+		if !report.IsEmpty() {
+			reports = append(reports, report)
+		}
+	}
+
+	return reports, nil
+}
+
+// participate returns error only if storage fails,
+// DTF errors are put into report
+func (uc *AutoParticipateUseCase) participate(
+	ctx context.Context,
+	session models.DtfUserSession,
+	raffles []models.Raffle,
+) (models.ParticipationReport, error) {
+	report := models.ParticipationReport{
+		Email:      session.Email,
+		TelegramId: session.TelegramId,
+	}
+
+	user, err := uc.userManager.BuildSession(ctx, session.Email)
+	if err != nil {
+		report.Error = err.Error()
+		return report, nil
+	}
+	self, err := uc.authRepo.SelfInfo(ctx, user)
+	if err != nil {
+		report.Error = err.Error()
+		return report, nil
+	}
+
+	done, err := uc.participationRepo.GetParticipated(ctx, session.Email)
+	if err != nil {
+		return report, err
+	}
+	rules, err := subscriberRules(ctx, uc.sourceRuleRepo, session.TelegramId)
+	if err != nil {
+		return report, err
+	}
+
+	attempts := 0
+	for _, r := range raffles {
+		// denied sources could be allowed later, so they are not stored
+		if done[r.Id] || r.Author.Id == self.Id || !rules.Allows(r.Post) {
+			continue
+		}
+		if attempts == maxParticipationsPerRun {
+			break
+		}
+		attempts++
+
+		result, err := uc.join(ctx, session.Email, self, r)
+		switch result.Status {
+		case models.ParticipationJoined:
+			report.Joined = append(report.Joined, result)
+		case models.ParticipationSkipped:
+			report.Skipped = append(report.Skipped, result)
+		case models.ParticipationRetrying:
+			slog.Warn("Raffle participation failed", "email", session.Email, "post_id", r.Id, "error", err)
+			// account can't do anything until relogin, raffle isn't to blame
+			if isSessionError(err) {
+				report.Error = err.Error()
+				return report, nil
+			}
+
+			failed, err := uc.participationRepo.RecordAttempt(ctx, result)
+			if err != nil {
+				return report, err
+			}
+			if failed < maxParticipationAttempts {
+				continue
+			}
+			result.Status = models.ParticipationFailed
+			report.Failed = append(report.Failed, result)
+		default:
+			report.Failed = append(report.Failed, result)
+		}
+
+		if err := uc.participationRepo.Save(ctx, result); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+// join takes part in raffle, it is skipped if account already commented it.
+// Error is returned only for failed participation.
+func (uc *AutoParticipateUseCase) join(
+	ctx context.Context,
+	email string,
+	self models.DtfUserInfo,
+	r models.Raffle,
+) (models.RaffleParticipation, error) {
+	result := models.RaffleParticipation{
+		Raffle:    r,
+		Email:     email,
+		CreatedAt: time.Now(),
+	}
+
+	// catalog doesn't store blocks, links in embeds and link blocks
+	// are checked for risk only on the fresh post
+	post, err := uc.postRepo.GetPostById(ctx, r.Id)
+	if err != nil {
+		result.Status = models.ParticipationRetrying
+		result.Reason = err.Error()
+		return result, err
+	}
+	r.Post = post
+	r = analyzeRaffles([]models.Raffle{r})[0]
+	result.Raffle = r
+
+	comments, err := uc.postRepo.GetComments(ctx, r.Post)
+	if err != nil {
+		result.Status = models.ParticipationRetrying
+		result.Reason = err.Error()
+		return result, err
+	}
+	if models.HasCommentFrom(comments, self.Id, "") {
+		result.Status = models.ParticipationSkipped
+		result.Reason = "уже есть комментарий от аккаунта"
+		return result, nil
+	}
+
+	result.Participation, err = uc.likeAndPost.Execute(ctx, email, r.Post)
+	switch {
+	case err == nil:
+		result.Status = models.ParticipationJoined
+	case errors.Is(err, domain.ErrRaffleTooRisky):
+		result.Status = models.ParticipationSkipped
+		result.Reason = strings.Join(r.Risk.Reasons, ", ")
+		err = nil
+	case !result.Participation.Liked && !result.Participation.Commented:
+		result.Status = models.ParticipationRetrying
+		result.Reason = err.Error()
+	default:
+		result.Status = models.ParticipationFailed
+		result.Reason = err.Error()
+	}
+	return result, err
+}
+
+// isSessionError means account can't do anything until relogin
+func isSessionError(err error) bool {
+	return errors.Is(err, domain.ErrSessionExpired) || errors.Is(err, domain.ErrUnauthorized)
+}
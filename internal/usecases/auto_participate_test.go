@@ -0,0 +1,165 @@
+package usecases
+
+import (
+	"context"
+	"dtf/game_draw/internal/domain/models"
+	"dtf/game_draw/pkg/dtfapi/dtfapitest"
+	"net/http"
+	"testing"
+)
+
+const participantTelegramId int64 = 100
+
+var (
+	participant = dtfapitest.User{Id: 1, Name: "Participant", Email: "participant@example.com", Password: "secret"}
+	organizer   = dtfapitest.User{Id: 2, Name: "Organizer", Email: "organizer@example.com", Password: "secret"}
+)
+
+// newParticipationEnv has opted-in participant and stored raffle of organizer
+func newParticipationEnv(t *testing.T) (*testEnv, *AutoParticipateUseCase, dtfapitest.Post) {
+	t.Helper()
+
+	env := newTestEnv(t)
+	env.server.AddUser(organizer)
+	env.addAccount(t, participant, participantTelegramId)
+	if _, err := env.sessionRepo.SetAutoParticipate(context.Background(), participantTelegramId, true); err != nil {
+		t.Fatalf("enable auto participation: %v", err)
+	}
+
+	post := env.server.AddPost(dtfapitest.Post{
+		Title:    "Розыгрыш ключа Hades",
+		AuthorId: organizer.Id,
+		Blocks: []dtfapitest.Block{
+			dtfapitest.TextBlock("Для участия поставьте лайк и напишите в комментариях «Участвую»."),
+		},
+	})
+	if report := env.syncRaffles(t); len(report.New) != 1 {
+		t.Fatalf("synced %d raffles, want 1", len(report.New))
+	}
+
+	uc := NewAutoParticipateUseCase(
+		env.sessionRepo,
+		env.authRepo,
+		env.raffleRepo,
+		env.postRepo,
+		env.participationRepo,
+		env.sourceRuleRepo,
+		env.userManager,
+		NewLikeAndPostToRafflePostUseCase(env.postRepo, env.userManager),
+	)
+	return env, uc, post
+}
+
+func runParticipation(t *testing.T, uc *AutoParticipateUseCase) []models.ParticipationReport {
+	t.Helper()
+
+	reports, err := uc.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("auto participate: %v", err)
+	}
+	return reports
+}
+
+func TestAutoParticipateJoins(t *testing.T) {
+	env, uc, post := newParticipationEnv(t)
+
+	reports := runParticipation(t, uc)
+	if len(reports) != 1 || len(reports[0].Joined) != 1 {
+		t.Fatalf("reports = %+v, want one joined raffle", reports)
+	}
+	if reports[0].TelegramId != participantTelegramId {
+		t.Errorf("report telegram id = %d, want %d", reports[0].TelegramId, participantTelegramId)
+	}
+
+	if !env.server.Reacted(post.Id, participant.Id) {
+		t.Error("raffle post wasn't liked")
+	}
+	comments := env.server.Comments(post.Id)
+	if len(comments) != 1 || comments[0].AuthorId != participant.Id || comments[0].Text != "Участвую" {
+		t.Errorf("comments = %+v, want one comment «Участвую» of participant", comments)
+	}
+
+	// raffle is done, the next run has nothing to do
+	if reports := runParticipation(t, uc); len(reports) != 0 {
+		t.Errorf("second run reports = %+v, want none", reports)
+	}
+	if comments := env.server.Comments(post.Id); len(comments) != 1 {
+		t.Errorf("comments after second run = %d, want 1", len(comments))
+	}
+}
+
+func TestAutoParticipateSkipsAlreadyCommented(t *testing.T) {
+	env, uc, post := newParticipationEnv(t)
+	env.server.AddComment(dtfapitest.Comment{PostId: post.Id, AuthorId: participant.Id, Text: "Хочу"})
+
+	reports := runParticipation(t, uc)
+	if len(reports) != 1 || len(reports[0].Skipped) != 1 {
+		t.Fatalf("reports = %+v, want one skipped raffle", reports)
+	}
+	if comments := env.server.Comments(post.Id); len(comments) != 1 {
+		t.Errorf("comments = %d, want only the manual one", len(comments))
+	}
+	if env.server.Reacted(post.Id, participant.Id) {
+		t.Error("raffle post was liked")
+	}
+}
+
+func TestAutoParticipateReportsFailureOnce(t *testing.T) {
+	env, uc, post := newParticipationEnv(t)
+	env.server.InjectFault(dtfapitest.Fault{
+		Route:  "POST /v2.5/content/{post_id}/react",
+		Status: http.StatusBadGateway,
+	})
+
+	for run := 1; run < maxParticipationAttempts; run++ {
+		if reports := runParticipation(t, uc); len(reports) != 0 {
+			t.Fatalf("run %d reports = %+v, want none while retrying", run, reports)
+		}
+	}
+
+	reports := runParticipation(t, uc)
+	if len(reports) != 1 || len(reports[0].Failed) != 1 {
+		t.Fatalf("last attempt reports = %+v, want one failed raffle", reports)
+	}
+
+	env.server.ClearFaults()
+	if reports := runParticipation(t, uc); len(reports) != 0 {
+		t.Errorf("reports after failure = %+v, want none", reports)
+	}
+	if comments := env.server.Comments(post.Id); len(comments) != 0 {
+		t.Errorf("comments = %d, want none", len(comments))
+	}
+}
+
+func TestAutoParticipateChecksEmbeds(t *testing.T) {
+	env, uc, _ := newParticipationEnv(t)
+
+	// catalog keeps only text, the embed is seen on the fresh post
+	phishing := env.server.AddPost(dtfapitest.Post{
+		Title:    "Розыгрыш скинов CS2",
+		AuthorId: organizer.Id,
+		Blocks: []dtfapitest.Block{
+			dtfapitest.TextBlock("Для участия поставьте лайк и заберите скин по ссылке."),
+			{Type: "embed", Data: map[string]any{
+				"embed": map[string]any{"type": "embed", "data": map[string]any{"url": "https://steamcommunnity.com/tradeoffer/new"}},
+			}},
+		},
+	})
+	if report := env.syncRaffles(t); len(report.New) != 1 {
+		t.Fatalf("synced %d raffles, want 1", len(report.New))
+	}
+
+	reports := runParticipation(t, uc)
+	if len(reports) != 1 || len(reports[0].Joined) != 1 || len(reports[0].Skipped) != 1 {
+		t.Fatalf("reports = %+v, want one joined and one skipped raffle", reports)
+	}
+	if skipped := reports[0].Skipped[0]; skipped.Raffle.Id != int64(phishing.Id) {
+		t.Errorf("skipped raffle = %d, want %d", skipped.Raffle.Id, phishing.Id)
+	}
+	if env.server.Reacted(phishing.Id, participant.Id) {
+		t.Error("phishing raffle was liked")
+	}
+	if comments := env.server.Comments(phishing.Id); len(comments) != 0 {
+		t.Errorf("comments = %d, want none", len(comments))
+	}
+}
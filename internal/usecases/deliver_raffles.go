@@ -0,0 +1,87 @@
+package usecases
+
+import (
+	"context"
+	"dtf/game_draw/internal/domain/models"
+	"dtf/game_draw/internal/domain/repositories"
+	"time"
+)
+
+// DeliverRafflesUseCase tracks which raffles every subscriber already got,
+// so nothing is sent twice
+type DeliverRafflesUseCase struct {
+	raffleRepo     repositories.RaffleRepository
+	deliveryRepo   repositories.RaffleDeliveryRepository
+	sourceRuleRepo repositories.SourceRuleRepository
+}
+
+func NewDeliverRafflesUseCase(
+	raffleRepo repositories.RaffleRepository,
+	deliveryRepo repositories.RaffleDeliveryRepository,
+	sourceRuleRepo repositories.SourceRuleRepository,
+) *DeliverRafflesUseCase {
+	return &DeliverRafflesUseCase{
+		raffleRepo:     raffleRepo,
+		deliveryRepo:   deliveryRepo,
+		sourceRuleRepo: sourceRuleRepo,
+	}
+}
+
+// Pending returns open raffles which were not sent to subscriber yet
+// and are allowed by their source rules, newest first
+func (uc *DeliverRafflesUseCase) Pending(ctx context.Context, telegramId int64) ([]models.Raffle, error) {
+	raffles, err := uc.raffleRepo.GetByStates(
+		ctx,
+		models.RaffleStateNew,
+		models.RaffleStateActive,
+		models.RaffleStateEndingSoon,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	delivered, err := uc.deliveryRepo.GetDelivered(ctx, telegramId)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := subscriberRules(ctx, uc.sourceRuleRepo, telegramId)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []models.Raffle
+	for _, r := range rules.Apply(raffles) {
+		if !delivered[r.Id] {
+			pending = append(pending, r)
+		}
+	}
+
+	return withReputation(ctx, uc.raffleRepo, analyzeRaffles(pending))
+}
+
+func (uc *DeliverRafflesUseCase) MarkDelivered(ctx context.Context, telegramId int64, raffles []models.Raffle) error {
+	ids := make([]int64, len(raffles))
+	for i, r := range raffles {
+		ids[i] = r.Id
+	}
+	return uc.deliveryRepo.MarkDelivered(ctx, telegramId, ids, time.Now())
+}
+
+// ResultsRecipients groups raffles by subscribers they were sent to,
+// only those subscribers care about results
+func (uc *DeliverRafflesUseCase) ResultsRecipients(
+	ctx context.Context,
+	raffles []models.Raffle,
+) (map[int64][]models.Raffle, error) {
+	recipients := make(map[int64][]models.Raffle)
+	for _, r := range raffles {
+		ids, err := uc.deliveryRepo.GetRecipients(ctx, r.Id)
+		if err != nil {
+			return recipients, err
+		}
+		for _, id := range ids {
+			recipients[id] = append(recipients[id], r)
+		}
+	}
+	return recipients, nil
+}
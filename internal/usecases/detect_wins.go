@@ -0,0 +1,185 @@
+package usecases
+
+import (
+	"context"
+	"dtf/game_draw/internal/domain/managers"
+	"dtf/game_draw/internal/domain/models"
+	"dtf/game_draw/internal/domain/raffle"
+	"dtf/game_draw/internal/domain/repositories"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// DetectWinsUseCase looks for our accounts in published raffle results
+type DetectWinsUseCase struct {
+	sessionRepo repositories.DtfSessionRepository
+	authRepo    repositories.AuthRepository
+	raffleRepo  repositories.RaffleRepository
+	postRepo    repositories.PostRepository
+	winRepo     repositories.RaffleWinRepository
+	userManager managers.UserManager
+}
+
+func NewDetectWinsUseCase(
+	sessionRepo repositories.DtfSessionRepository,
+	authRepo repositories.AuthRepository,
+	raffleRepo repositories.RaffleRepository,
+	postRepo repositories.PostRepository,
+	winRepo repositories.RaffleWinRepository,
+	userManager managers.UserManager,
+) *DetectWinsUseCase {
+	return &DetectWinsUseCase{
+		sessionRepo: sessionRepo,
+		authRepo:    authRepo,
+		raffleRepo:  raffleRepo,
+		postRepo:    postRepo,
+		winRepo:     winRepo,
+		userManager: userManager,
+	}
+}
+
+type winAccount struct {
+	session models.DtfUserSession
+	info    models.DtfUserInfo
+}
+
+// Execute checks results published since given date
+// and returns wins which were not found before
+func (uc *DetectWinsUseCase) Execute(ctx context.Context, since time.Time) ([]models.RaffleWin, error) {
+	raffles, err := uc.raffleRepo.GetByStates(ctx, models.RaffleStateResultsPublished)
+	if err != nil {
+		return nil, err
+	}
+	raffles = slices.DeleteFunc(raffles, func(r models.Raffle) bool {
+		return r.Results.PublishedAt.Before(since)
+	})
+	// accounts are resolved via api, no point to do it for nothing
+	if len(raffles) == 0 {
+		return nil, nil
+	}
+
+	accounts, err := uc.accounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(accounts) == 0 {
+		return nil, nil
+	}
+
+	var wins []models.RaffleWin
+	for _, r := range raffles {
+		found, err := uc.checkRaffle(ctx, r, accounts)
+		if err != nil {
+			return wins, err
+		}
+		wins = append(wins, found...)
+	}
+
+	return wins, nil
+}
+
+// accounts resolves DTF identity of every stored session,
+// broken sessions are skipped
+func (uc *DetectWinsUseCase) accounts(ctx context.Context) ([]winAccount, error) {
+	sessions, err := uc.sessionRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var accounts []winAccount
+	for _, session := range sessions {
+		if session.NeedsRelogin() {
+			continue
+		}
+		user, err := uc.userManager.BuildSession(ctx, session.Email)
+		if err != nil {
+			slog.Warn("Cant build session for wins check", "email", session.Email, "error", err)
+			continue
+		}
+		info, err := uc.authRepo.SelfInfo(ctx, user)
+		if err != nil {
+			slog.Warn("Cant resolve dtf user for wins check", "email", session.Email, "error", err)
+			continue
+		}
+		// telegram link is stored only in repository
+		user.TelegramId = session.TelegramId
+		accounts = append(accounts, winAccount{session: user, info: info})
+	}
+
+	return accounts, nil
+}
+
+// checkRaffle looks into results post, then into organizer comments under it
+func (uc *DetectWinsUseCase) checkRaffle(
+	ctx context.Context,
+	r models.Raffle,
+	accounts []winAccount,
+) ([]models.RaffleWin, error) {
+	var wins []models.RaffleWin
+	var comments []models.Comment
+	commentsLoaded := false
+
+	for _, account := range accounts {
+		// own raffle
+		if account.info.Id == r.Author.Id {
+			continue
+		}
+		exists, err := uc.winRepo.Exists(ctx, r.Id, account.session.Email)
+		if err != nil {
+			return wins, err
+		}
+		if exists {
+			continue
+		}
+
+		match, uri, ok := raffle.WinnerMatch{}, "", false
+		if match, ok = raffle.FindWinner(r.Results.Title+"\n"+r.Results.Text, account.info); ok {
+			uri = r.Results.Uri
+		}
+		// bare name could be a namesake, organizer comments may have a link or mention
+		if !ok || match.NameOnly {
+			if !commentsLoaded {
+				commentsLoaded = true
+				comments, err = uc.postRepo.GetComments(ctx, models.Post{Id: r.Results.PostId, Uri: r.Results.Uri})
+				if err != nil {
+					slog.Warn("Cant load results comments", "post_id", r.Results.PostId, "error", err)
+				}
+			}
+			for _, comment := range models.CommentsFrom(comments, r.Author.Id) {
+				commentMatch, found := raffle.FindWinner(comment.Text, account.info)
+				if !found || (ok && commentMatch.NameOnly) {
+					continue
+				}
+				match, ok = commentMatch, true
+				uri = fmt.Sprintf("%s?comment=%d", r.Results.Uri, comment.Id)
+				if !match.NameOnly {
+					break
+				}
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		win := models.RaffleWin{
+			RafflePostId: r.Id,
+			RaffleTitle:  r.Title,
+			Email:        account.session.Email,
+			DtfUser:      account.info,
+			TelegramId:   account.session.TelegramId,
+			Fragment:     match.Fragment,
+			NameOnly:     match.NameOnly,
+			Uri:          uri,
+			FoundAt:      time.Now(),
+		}
+		if err := uc.winRepo.Save(ctx, win); err != nil {
+			return wins, err
+		}
+		slog.Info("Raffle win found", "post_id", r.Id, "email", win.Email)
+		wins = append(wins, win)
+	}
+
+	return wins, nil
+}
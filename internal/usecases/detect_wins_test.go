@@ -0,0 +1,32 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDetectWinsSkipsAccountsWithoutResults(t *testing.T) {
+	env := newTestEnv(t)
+	env.addAccount(t, participant, participantTelegramId)
+	selfRequests := env.server.RequestCount("GET /v2.1/subsite/me")
+
+	uc := NewDetectWinsUseCase(
+		env.sessionRepo,
+		env.authRepo,
+		env.raffleRepo,
+		env.postRepo,
+		env.winRepo,
+		env.userManager,
+	)
+	wins, err := uc.Execute(context.Background(), time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("detect wins: %v", err)
+	}
+	if len(wins) != 0 {
+		t.Errorf("wins = %+v, want none", wins)
+	}
+	if got := env.server.RequestCount("GET /v2.1/subsite/me"); got != selfRequests {
+		t.Errorf("self info requests = %d, want %d", got, selfRequests)
+	}
+}
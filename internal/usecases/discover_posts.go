@@ -0,0 +1,118 @@
+package usecases
+
+import (
+	"context"
+	"dtf/game_draw/internal/domain/models"
+	"dtf/game_draw/internal/domain/repositories"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// DefaultDiscoveryQueries are used if no queries were configured
+var DefaultDiscoveryQueries = []string{
+	"Розыгрыш",
+	"Раздача",
+	"Конкурс",
+	"Giveaway",
+	"Дарим ключи",
+	// results are searched too, they are linked to stored raffles
+	"Итоги розыгрыша",
+	"Победители",
+}
+
+// discoverPosts runs every query and merges results.
+// Posts are deduplicated by id, reposts are merged into their originals.
+// Originals of reposts which were not found are fetched by id,
+// if that fails the repost itself is kept.
+// Fails only if every query failed.
+func discoverPosts(
+	ctx context.Context,
+	postRepo repositories.PostRepository,
+	queries []string,
+	fromDate time.Time,
+) ([]models.Raffle, error) {
+	var errs []error
+	found := make(map[int64]*models.Raffle)
+	var order []int64 // keeps search order stable
+	var reposts []models.Raffle
+	repostIndex := make(map[int64]int)
+
+	for _, query := range queries {
+		posts, err := postRepo.SearchPosts(ctx, query, fromDate)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			errs = append(errs, fmt.Errorf("query %q: %w", query, err))
+			continue
+		}
+
+		for _, post := range posts {
+			if post.IsReply() {
+				if i, ok := repostIndex[post.Id]; ok {
+					reposts[i].MatchedQueries = append(reposts[i].MatchedQueries, query)
+					continue
+				}
+				repostIndex[post.Id] = len(reposts)
+				reposts = append(reposts, models.Raffle{Post: post, MatchedQueries: []string{query}})
+				continue
+			}
+
+			raffle, ok := found[post.Id]
+			if !ok {
+				found[post.Id] = &models.Raffle{Post: post}
+				order = append(order, post.Id)
+				raffle = found[post.Id]
+			}
+			if !slices.Contains(raffle.MatchedQueries, query) {
+				raffle.MatchedQueries = append(raffle.MatchedQueries, query)
+			}
+		}
+	}
+
+	if len(errs) == len(queries) && len(queries) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	for _, err := range errs {
+		slog.Warn("Discovery query failed", "error", err)
+	}
+
+	for _, repost := range reposts {
+		originalId := int64(*repost.RepliedTo)
+		original, ok := found[originalId]
+		if !ok {
+			// original could be published before fromDate or just not match queries
+			post, err := postRepo.GetPostById(ctx, originalId)
+			switch {
+			case err == nil:
+				original = &models.Raffle{Post: post}
+			case ctx.Err() != nil:
+				return nil, ctx.Err()
+			default:
+				// repost is better than nothing, it links to the original anyway
+				slog.Warn("Cant load original of repost", "post_id", repost.Id, "original_id", originalId, "error", err)
+				originalId = repost.Id
+				original = &models.Raffle{Post: repost.Post}
+			}
+			found[originalId] = original
+			order = append(order, originalId)
+		}
+		for _, query := range repost.MatchedQueries {
+			if !slices.Contains(original.MatchedQueries, query) {
+				original.MatchedQueries = append(original.MatchedQueries, query)
+			}
+		}
+	}
+
+	result := make([]models.Raffle, 0, len(order))
+	for _, id := range order {
+		raffle := found[id]
+		slog.Debug("Post discovered", "post_id", raffle.Id, "queries", raffle.MatchedQueries)
+		result = append(result, *raffle)
+	}
+
+	return result, nil
+}
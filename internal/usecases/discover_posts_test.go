@@ -0,0 +1,64 @@
+package usecases
+
+import (
+	"context"
+	"dtf/game_draw/pkg/dtfapi/dtfapitest"
+	"testing"
+	"time"
+)
+
+func TestDiscoverPostsKeepsReposts(t *testing.T) {
+	fromDate := time.Now().Add(-24 * time.Hour)
+	env := newTestEnv(t)
+	env.server.AddUser(organizer)
+	env.server.AddUser(participant)
+
+	// published before the window, found only through the repost
+	original := env.server.AddPost(dtfapitest.Post{
+		Title:    "Розыгрыш ключа Hades",
+		Date:     fromDate.Add(-time.Hour),
+		AuthorId: organizer.Id,
+	})
+	repost := env.server.AddPost(dtfapitest.Post{
+		Title:    "Розыгрыш ключа Hades",
+		AuthorId: participant.Id,
+		RepostId: &original.Id,
+	})
+	// original is deleted
+	missingId := 999999
+	orphan := env.server.AddPost(dtfapitest.Post{
+		Title:    "Розыгрыш ключа Celeste",
+		AuthorId: participant.Id,
+		RepostId: &missingId,
+	})
+
+	raffles, err := discoverPosts(context.Background(), env.postRepo, []string{"Розыгрыш", "ключа"}, fromDate)
+	if err != nil {
+		t.Fatalf("discoverPosts() error = %v", err)
+	}
+
+	ids := postIds(raffles)
+	want := []int64{int64(original.Id), int64(orphan.Id)}
+	if len(ids) != len(want) {
+		t.Fatalf("discoverPosts() = %v, want %v", ids, want)
+	}
+	for _, id := range want {
+		found := false
+		for _, r := range raffles {
+			if r.Id == id {
+				found = true
+				if len(r.MatchedQueries) != 2 {
+					t.Errorf("post %d matched queries = %v, want both", id, r.MatchedQueries)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("discoverPosts() = %v, want post %d", ids, id)
+		}
+	}
+	for _, r := range raffles {
+		if r.Id == int64(repost.Id) {
+			t.Errorf("repost %d is kept instead of its original", repost.Id)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package usecases
+
+import (
+	"context"
+	"dtf/game_draw/internal/domain/models"
+	"dtf/game_draw/internal/domain/repositories"
+	"log/slog"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// enrichRaffles replaces search versions of posts with full ones.
+// At most workers posts are fetched at once, dtf client limiter still applies.
+// If post can't be fetched, search version is kept.
+// Order of posts is preserved.
+func enrichRaffles(
+	ctx context.Context,
+	postRepo repositories.PostRepository,
+	raffles []models.Raffle,
+	workers int,
+) ([]models.Raffle, error) {
+	result := make([]models.Raffle, len(raffles))
+	copy(result, raffles)
+
+	g := errgroup.Group{}
+	g.SetLimit(max(workers, 1))
+
+	for i, raffle := range raffles {
+		if ctx.Err() != nil {
+			break
+		}
+
+		g.Go(func() error {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			fullPost, err := postRepo.GetPostById(ctx, raffle.Id)
+			if err != nil {
+				slog.Warn("Post enrichment failed, using search version", "post_id", raffle.Id, "error", err)
+				return nil
+			}
+			// each goroutine writes only its own index
+			result[i].Post = fullPost
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
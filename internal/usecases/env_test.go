@@ -0,0 +1,104 @@
+package usecases
+
+import (
+	"context"
+	iManagers "dtf/game_draw/internal/domain/managers"
+	"dtf/game_draw/internal/domain/models"
+	iRepo "dtf/game_draw/internal/domain/repositories"
+	"dtf/game_draw/internal/managers"
+	"dtf/game_draw/internal/repositories"
+	"dtf/game_draw/internal/storage"
+	"dtf/game_draw/internal/storage/sqlite/sqlitetest"
+	"dtf/game_draw/pkg/dtfapi"
+	"dtf/game_draw/pkg/dtfapi/dtfapitest"
+	"testing"
+)
+
+// testEnv wires use cases the same way as the app does,
+// but with fake DTF api and temporary database
+type testEnv struct {
+	server  *dtfapitest.Server
+	service *dtfapi.DtfService
+
+	telegramSubsRepo  iRepo.TelegramSubscribersRepository
+	sessionRepo       iRepo.DtfSessionRepository
+	postRepo          iRepo.PostRepository
+	authRepo          iRepo.AuthRepository
+	raffleRepo        iRepo.RaffleRepository
+	watermarkRepo     iRepo.WatermarkRepository
+	sourceRuleRepo    iRepo.SourceRuleRepository
+	participationRepo iRepo.RaffleParticipationRepository
+	winRepo           iRepo.RaffleWinRepository
+
+	userManager iManagers.UserManager
+}
+
+func newTestEnv(t *testing.T, opts ...dtfapitest.Option) *testEnv {
+	t.Helper()
+
+	server := dtfapitest.NewServer(opts...)
+	t.Cleanup(server.Close)
+
+	client := dtfapi.NewClient(context.Background(), server.ClientOptions()...)
+	t.Cleanup(func() { _ = client.Close() })
+	service := dtfapi.NewService(client.Client())
+
+	db := sqlitetest.NewDB(t)
+	sqlProvider := storage.NewProvider(db)
+	transactor := storage.NewSqlTransactor(db)
+
+	sessionRepo := repositories.NewSqliteUserSessionRepository(sqlProvider)
+	tokenSources := repositories.NewDtfTokenSources(service, sessionRepo)
+	authRepo := repositories.NewDtfAuthRepository(service, tokenSources)
+
+	return &testEnv{
+		server:            server,
+		service:           service,
+		telegramSubsRepo:  repositories.NewSqliteTelegramSubRepository(sqlProvider, transactor),
+		sessionRepo:       sessionRepo,
+		postRepo:          repositories.NewDtfPostRepository(service, tokenSources),
+		authRepo:          authRepo,
+		raffleRepo:        repositories.NewSqliteRaffleRepository(sqlProvider, transactor),
+		watermarkRepo:     repositories.NewSqliteWatermarkRepository(sqlProvider),
+		sourceRuleRepo:    repositories.NewSqliteSourceRuleRepository(sqlProvider),
+		participationRepo: repositories.NewSqliteRaffleParticipationRepository(sqlProvider),
+		winRepo:           repositories.NewSqliteRaffleWinRepository(sqlProvider),
+		userManager:       managers.NewUserSessionManager(sessionRepo, authRepo),
+	}
+}
+
+// addAccount creates DTF user and links its session to telegram subscriber
+func (env *testEnv) addAccount(t *testing.T, user dtfapitest.User, telegramId int64) models.DtfUserSession {
+	t.Helper()
+	ctx := context.Background()
+
+	env.server.AddUser(user)
+	if err := env.telegramSubsRepo.RegisterUser(ctx, telegramId); err != nil {
+		t.Fatalf("register subscriber: %v", err)
+	}
+	link := NewLinkDtfAccountUseCase(env.authRepo, env.sessionRepo)
+	if err := link.Execute(ctx, telegramId, user.Email, user.Password); err != nil {
+		t.Fatalf("link account: %v", err)
+	}
+
+	session, err := env.sessionRepo.GetByEmail(ctx, user.Email)
+	if err != nil {
+		t.Fatalf("get session: %v", err)
+	}
+	return session
+}
+
+func (env *testEnv) activeRaffles(opts ...ActiveRafflesOption) *GetActiveRafflePostsUseCase {
+	return NewGetActiveRafflePostsUseCase(env.postRepo, opts...)
+}
+
+func (env *testEnv) syncRaffles(t *testing.T) models.RaffleSyncReport {
+	t.Helper()
+
+	report, err := NewSyncRafflesUseCase(env.activeRaffles(), env.postRepo, env.raffleRepo, env.watermarkRepo).
+		Execute(context.Background())
+	if err != nil {
+		t.Fatalf("sync raffles: %v", err)
+	}
+	return report
+}
@@ -3,61 +3,115 @@ package usecases
 import (
 	"context"
 	"dtf/game_draw/internal/domain/models"
+	"dtf/game_draw/internal/domain/raffle"
 	"dtf/game_draw/internal/domain/repositories"
-	"strings"
+	"log/slog"
+	"slices"
 	"time"
 )
 
 type GetActiveRafflePostsUseCase struct {
-	postRepo repositories.PostRepository
+	postRepo      repositories.PostRepository
+	queries       []string
+	enrichWorkers int // 0 disables enrichment
 }
 
-func NewGetActiveRafflePostsUseCase(repo repositories.PostRepository) *GetActiveRafflePostsUseCase {
-	return &GetActiveRafflePostsUseCase{
+type ActiveRafflesOption func(*GetActiveRafflePostsUseCase)
+
+// WithContentEnrichment fetches full content of every found raffle,
+// because search may return truncated blocks.
+func WithContentEnrichment(workers int) ActiveRafflesOption {
+	return func(uc *GetActiveRafflePostsUseCase) {
+		uc.enrichWorkers = workers
+	}
+}
+
+// WithDiscoveryQueries replaces DefaultDiscoveryQueries
+func WithDiscoveryQueries(queries ...string) ActiveRafflesOption {
+	return func(uc *GetActiveRafflePostsUseCase) {
+		if len(queries) > 0 {
+			uc.queries = queries
+		}
+	}
+}
+
+func NewGetActiveRafflePostsUseCase(
+	repo repositories.PostRepository,
+	opts ...ActiveRafflesOption,
+) *GetActiveRafflePostsUseCase {
+	uc := &GetActiveRafflePostsUseCase{
 		postRepo: repo,
+		queries:  DefaultDiscoveryQueries,
+	}
+	for _, opt := range opts {
+		opt(uc)
 	}
+	return uc
 }
 
-func (uc *GetActiveRafflePostsUseCase) Execute(ctx context.Context, fromDate time.Time) ([]models.Post, error) {
-	// getting all raffle (Розыгрыш) posts
-	posts, err := uc.postRepo.SearchPosts(ctx, "Розыгрыш", fromDate)
+// Execute returns ongoing raffles published since fromDate, newest first
+func (uc *GetActiveRafflePostsUseCase) Execute(ctx context.Context, fromDate time.Time) ([]models.Raffle, error) {
+	active, _, err := uc.Discover(ctx, fromDate)
+	return active, err
+}
+
+// Discover returns ongoing raffles and results posts published since fromDate,
+// both newest first
+func (uc *GetActiveRafflePostsUseCase) Discover(
+	ctx context.Context,
+	fromDate time.Time,
+) (active []models.Raffle, results []models.Raffle, err error) {
+	// getting all raffle posts by every query, repo walks through every search page
+	raffles, err := discoverPosts(ctx, uc.postRepo, uc.queries, fromDate)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	// dropping everything what is not a raffle,
+	// search version may be truncated so enriched posts are classified again
+	raffles = keepRaffles(raffles)
+	if uc.enrichWorkers > 0 {
+		raffles, err = enrichRaffles(ctx, uc.postRepo, raffles, uc.enrichWorkers)
+		if err != nil {
+			return nil, nil, err
+		}
+		raffles = keepRaffles(raffles)
 	}
 
-	// filtering and keeping only ongoing posts
-	var result []models.Post
-	for _, post := range posts {
-		if isEnded(post.Title) || post.IsReply() {
+	for _, r := range raffles {
+		if r.Classification.Kind == models.RaffleResults {
+			results = append(results, r)
 			continue
 		}
-		result = append(result, post)
+		active = append(active, r)
 	}
+	active = analyzeRaffles(active)
 
-	return result, nil
-}
+	newestFirst := func(a, b models.Raffle) int {
+		return b.PublishedAt.Compare(a.PublishedAt)
+	}
+	slices.SortStableFunc(active, newestFirst)
+	slices.SortStableFunc(results, newestFirst)
 
-var endedRaffleKeywords = []string{
-	"завершен",
-	"завершён",
-	"закончен",
-	"закончился",
-	"итоги",
-	"подведены итоги",
-	"победители",
-	"разыграли",
-	"финал",
-	"результа",
-	"завершили",
+	return active, results, nil
 }
 
-func isEnded(title string) bool {
-	title = strings.ToLower(title)
-	for _, keyWord := range endedRaffleKeywords {
-		if strings.Contains(title, keyWord) {
-			return true
+// keepRaffles classifies posts and keeps active raffles and results posts
+func keepRaffles(raffles []models.Raffle) []models.Raffle {
+	var result []models.Raffle
+	for _, r := range raffles {
+		r.Classification = raffle.Classify(r.Post)
+		if r.Classification.Kind == models.NotRaffle {
+			slog.Debug(
+				"Post skipped",
+				"post_id", r.Id,
+				"kind", r.Classification.Kind,
+				"reasons", r.Classification.Reasons,
+			)
+			continue
 		}
+		result = append(result, r)
 	}
 
-	return false
+	return result
 }
@@ -0,0 +1,78 @@
+package usecases
+
+import (
+	"context"
+	"dtf/game_draw/internal/domain/models"
+	"dtf/game_draw/pkg/dtfapi/dtfapitest"
+	"testing"
+	"time"
+)
+
+func TestDiscoverFiltersRaffles(t *testing.T) {
+	fromDate := time.Now().Add(-24 * time.Hour)
+	env := newTestEnv(t, dtfapitest.WithPageSize(2))
+	env.server.AddUser(organizer)
+
+	raffle := env.server.AddPost(dtfapitest.Post{
+		Title:    "Розыгрыш ключа Hades",
+		AuthorId: organizer.Id,
+		Blocks: []dtfapitest.Block{
+			dtfapitest.TextBlock("Для участия поставьте лайк и напишите в комментариях «Участвую». Итоги подведу в пятницу."),
+		},
+	})
+	results := env.server.AddPost(dtfapitest.Post{
+		Title:    "Итоги розыгрыша ключа Hades",
+		AuthorId: organizer.Id,
+		Blocks: []dtfapitest.Block{
+			dtfapitest.TextBlock("Победитель выбран рандомом: @alex, поздравляем!"),
+		},
+	})
+	// found by queries, but not raffles
+	env.server.AddPost(dtfapitest.Post{
+		Title:    "Первоапрельский розыгрыш: Valve анонсировала Half-Life 3",
+		AuthorId: organizer.Id,
+		Blocks:   []dtfapitest.Block{dtfapitest.TextBlock("Студия подшутила над фанатами.")},
+	})
+	env.server.AddPost(dtfapitest.Post{
+		Title:    "Победители The Game Awards",
+		AuthorId: organizer.Id,
+		Blocks:   []dtfapitest.Block{dtfapitest.TextBlock("Игрой года стала Astro Bot.")},
+	})
+	// too old
+	env.server.AddPost(dtfapitest.Post{
+		Title:    "Розыгрыш ключа Celeste",
+		Date:     fromDate.Add(-time.Hour),
+		AuthorId: organizer.Id,
+		Blocks:   []dtfapitest.Block{dtfapitest.TextBlock("Лайк и комментарий для участия.")},
+	})
+
+	active, found, err := env.activeRaffles(WithContentEnrichment(2)).Discover(context.Background(), fromDate)
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+
+	if len(active) != 1 || active[0].Id != int64(raffle.Id) {
+		t.Fatalf("active = %v, want raffle %d", postIds(active), raffle.Id)
+	}
+	if !active[0].Conditions.Like || !active[0].Conditions.Comment || active[0].Conditions.CommentText != "Участвую" {
+		t.Errorf("conditions = %+v, want like and comment «Участвую»", active[0].Conditions)
+	}
+	if active[0].Author.Id != organizer.Id {
+		t.Errorf("author id = %d, want %d", active[0].Author.Id, organizer.Id)
+	}
+
+	if len(found) != 1 || found[0].Id != int64(results.Id) {
+		t.Errorf("results = %v, want post %d", postIds(found), results.Id)
+	}
+	if len(found) == 1 && found[0].Classification.Kind != models.RaffleResults {
+		t.Errorf("results kind = %v, want %v", found[0].Classification.Kind, models.RaffleResults)
+	}
+}
+
+func postIds(raffles []models.Raffle) []int64 {
+	ids := make([]int64, 0, len(raffles))
+	for _, r := range raffles {
+		ids = append(ids, r.Id)
+	}
+	return ids
+}
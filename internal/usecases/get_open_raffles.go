@@ -0,0 +1,46 @@
+package usecases
+
+import (
+	"context"
+	"dtf/game_draw/internal/domain/models"
+	"dtf/game_draw/internal/domain/repositories"
+)
+
+// GetOpenRafflesUseCase answers from local raffle catalog,
+// without going to DTF
+type GetOpenRafflesUseCase struct {
+	raffleRepo     repositories.RaffleRepository
+	sourceRuleRepo repositories.SourceRuleRepository
+}
+
+func NewGetOpenRafflesUseCase(
+	raffleRepo repositories.RaffleRepository,
+	sourceRuleRepo repositories.SourceRuleRepository,
+) *GetOpenRafflesUseCase {
+	return &GetOpenRafflesUseCase{
+		raffleRepo:     raffleRepo,
+		sourceRuleRepo: sourceRuleRepo,
+	}
+}
+
+// Execute returns raffles subscriber still can participate in, newest first.
+// Catalog keeps raffles of every source, rules are applied on reading,
+// so personal allow rule can override admin's deny.
+func (uc *GetOpenRafflesUseCase) Execute(ctx context.Context, telegramId int64) ([]models.Raffle, error) {
+	raffles, err := uc.raffleRepo.GetByStates(
+		ctx,
+		models.RaffleStateNew,
+		models.RaffleStateActive,
+		models.RaffleStateEndingSoon,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := subscriberRules(ctx, uc.sourceRuleRepo, telegramId)
+	if err != nil {
+		return nil, err
+	}
+
+	return withReputation(ctx, uc.raffleRepo, analyzeRaffles(rules.Apply(raffles)))
+}
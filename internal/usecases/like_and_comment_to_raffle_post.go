@@ -2,11 +2,18 @@ package usecases
 
 import (
 	"context"
+	"dtf/game_draw/internal/domain"
 	"dtf/game_draw/internal/domain/managers"
 	"dtf/game_draw/internal/domain/models"
+	"dtf/game_draw/internal/domain/raffle"
 	"dtf/game_draw/internal/domain/repositories"
+	"fmt"
+	"strings"
 )
 
+// defaultCommentText is used when organizer didn't ask for specific text
+const defaultCommentText = "Участвую"
+
 type LikeAndPostToRafflePostUseCase struct {
 	postRepo    repositories.PostRepository
 	userManager managers.UserManager
@@ -22,21 +29,50 @@ func NewLikeAndPostToRafflePostUseCase(
 	}
 }
 
-func (uc *LikeAndPostToRafflePostUseCase) Execute(ctx context.Context, userEmail string, post models.Post) error {
+// Execute does what raffle conditions ask for.
+// If no conditions were recognized, post is liked and commented as usual.
+// Conditions which can't be done automatically are returned in ManualActions.
+// High risk raffles are not touched, domain.ErrRaffleTooRisky is returned.
+// Post must be fetched from DTF with blocks, catalog raffles don't have them.
+func (uc *LikeAndPostToRafflePostUseCase) Execute(
+	ctx context.Context,
+	userEmail string,
+	post models.Post,
+) (models.Participation, error) {
+	conditions := raffle.ExtractConditions(post)
+	participation := models.Participation{
+		ManualActions: conditions.ManualActions(),
+	}
+
+	if risk := raffle.AssessRisk(post); risk.Level == models.RiskHigh {
+		return participation, fmt.Errorf("%w: %s", domain.ErrRaffleTooRisky, strings.Join(risk.Reasons, ", "))
+	}
+
 	user, err := uc.userManager.BuildSession(ctx, userEmail)
 	if err != nil {
-		return err
+		return participation, err
 	}
 
-	err = uc.postRepo.ReactToPost(ctx, user, post)
-	if err != nil {
-		return err
+	if conditions.Like || conditions.IsEmpty() {
+		err = uc.postRepo.ReactToPost(ctx, user, post)
+		if err != nil {
+			return participation, err
+		}
+		participation.Liked = true
 	}
 
-	err = uc.postRepo.PostComment(ctx, user, post, "Участвую")
-	if err != nil {
-		return err
+	if conditions.Comment || conditions.IsEmpty() {
+		text := conditions.CommentText
+		if text == "" {
+			text = defaultCommentText
+		}
+		err = uc.postRepo.PostComment(ctx, user, post, text)
+		if err != nil {
+			return participation, err
+		}
+		participation.Commented = true
+		participation.CommentText = text
 	}
 
-	return nil
+	return participation, nil
 }
@@ -0,0 +1,38 @@
+package usecases
+
+import (
+	"context"
+	"dtf/game_draw/internal/domain/repositories"
+)
+
+// LinkDtfAccountUseCase links DTF account to telegram subscriber,
+// so the subscriber can switch automatic participation and gets its reports
+type LinkDtfAccountUseCase struct {
+	authRepo    repositories.AuthRepository
+	sessionRepo repositories.DtfSessionRepository
+}
+
+func NewLinkDtfAccountUseCase(
+	authRepo repositories.AuthRepository,
+	sessionRepo repositories.DtfSessionRepository,
+) *LinkDtfAccountUseCase {
+	return &LinkDtfAccountUseCase{
+		authRepo:    authRepo,
+		sessionRepo: sessionRepo,
+	}
+}
+
+// Execute logs in to prove the account belongs to subscriber,
+// then saves fresh session linked to telegramId.
+// Wrong password doesn't touch stored session of the account.
+func (uc *LinkDtfAccountUseCase) Execute(ctx context.Context, telegramId int64, email, password string) error {
+	session, err := uc.authRepo.Login(ctx, email, password)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.sessionRepo.Save(ctx, session); err != nil {
+		return err
+	}
+	return uc.sessionRepo.LinkTelegram(ctx, email, telegramId)
+}
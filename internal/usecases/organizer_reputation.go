@@ -0,0 +1,41 @@
+package usecases
+
+import (
+	"context"
+	"dtf/game_draw/internal/domain/models"
+	"dtf/game_draw/internal/domain/raffle"
+	"dtf/game_draw/internal/domain/repositories"
+	"time"
+)
+
+// withReputation fills organizer reputation from raffle history in the catalog
+func withReputation(
+	ctx context.Context,
+	raffleRepo repositories.RaffleRepository,
+	raffles []models.Raffle,
+) ([]models.Raffle, error) {
+	seen := make(map[int]bool)
+	var authorIds []int
+	for _, r := range raffles {
+		if r.Author.Id != 0 && !seen[r.Author.Id] {
+			seen[r.Author.Id] = true
+			authorIds = append(authorIds, r.Author.Id)
+		}
+	}
+	if len(authorIds) == 0 {
+		return raffles, nil
+	}
+
+	history, err := raffleRepo.GetByAuthors(ctx, authorIds...)
+	if err != nil {
+		return raffles, err
+	}
+
+	reputations := raffle.Reputations(history, time.Now())
+	for i := range raffles {
+		if raffles[i].Author.Id != 0 {
+			raffles[i].Organizer = reputations[raffles[i].Author.Id]
+		}
+	}
+	return raffles, nil
+}
@@ -0,0 +1,61 @@
+package usecases
+
+import (
+	"context"
+	"dtf/game_draw/internal/domain/models"
+	"dtf/game_draw/internal/domain/repositories"
+	"time"
+)
+
+// SourceRulesUseCase manages allow and deny lists of authors and subsites
+type SourceRulesUseCase struct {
+	sourceRuleRepo repositories.SourceRuleRepository
+}
+
+func NewSourceRulesUseCase(sourceRuleRepo repositories.SourceRuleRepository) *SourceRulesUseCase {
+	return &SourceRulesUseCase{
+		sourceRuleRepo: sourceRuleRepo,
+	}
+}
+
+// Rules returns global and personal rules of subscriber
+func (uc *SourceRulesUseCase) Rules(ctx context.Context, telegramId int64) (models.SourceRules, error) {
+	return subscriberRules(ctx, uc.sourceRuleRepo, telegramId)
+}
+
+func (uc *SourceRulesUseCase) Set(
+	ctx context.Context,
+	owner int64,
+	kind models.SourceKind,
+	sourceId int,
+	mode models.SourceMode,
+) error {
+	return uc.sourceRuleRepo.Save(ctx, models.SourceRule{
+		Owner:     owner,
+		Kind:      kind,
+		SourceId:  sourceId,
+		Mode:      mode,
+		CreatedAt: time.Now(),
+	})
+}
+
+func (uc *SourceRulesUseCase) Remove(
+	ctx context.Context,
+	owner int64,
+	kind models.SourceKind,
+	sourceId int,
+) error {
+	return uc.sourceRuleRepo.Delete(ctx, owner, kind, sourceId)
+}
+
+// subscriberRules loads rules applied to raffles shown to subscriber
+func subscriberRules(
+	ctx context.Context,
+	repo repositories.SourceRuleRepository,
+	telegramId int64,
+) (models.SourceRules, error) {
+	if repo == nil {
+		return nil, nil
+	}
+	return repo.GetByOwners(ctx, models.GlobalRulesOwner, telegramId)
+}
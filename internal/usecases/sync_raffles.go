@@ -0,0 +1,205 @@
+package usecases
+
+import (
+	"context"
+	"dtf/game_draw/internal/domain"
+	"dtf/game_draw/internal/domain/models"
+	"dtf/game_draw/internal/domain/raffle"
+	"dtf/game_draw/internal/domain/repositories"
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+const (
+	rafflesWatermark = "raffles_sync"
+	// first sync looks that far back
+	defaultSyncLookback = 24 * time.Hour
+	// DTF search shows posts with a delay, so windows overlap a bit,
+	// catalog ignores already known posts anyway
+	syncOverlap = time.Hour
+	// open raffles are fetched again to refresh their counters
+	counterRefreshWorkers = 4
+)
+
+// SyncRafflesUseCase stores discovered raffles and moves known ones
+// through their lifecycle
+type SyncRafflesUseCase struct {
+	activeRafflesUseCase *GetActiveRafflePostsUseCase
+	postRepo             repositories.PostRepository
+	raffleRepo           repositories.RaffleRepository
+	watermarkRepo        repositories.WatermarkRepository
+}
+
+func NewSyncRafflesUseCase(
+	activeRafflesUseCase *GetActiveRafflePostsUseCase,
+	postRepo repositories.PostRepository,
+	raffleRepo repositories.RaffleRepository,
+	watermarkRepo repositories.WatermarkRepository,
+) *SyncRafflesUseCase {
+	return &SyncRafflesUseCase{
+		activeRafflesUseCase: activeRafflesUseCase,
+		postRepo:             postRepo,
+		raffleRepo:           raffleRepo,
+		watermarkRepo:        watermarkRepo,
+	}
+}
+
+// Execute fetches posts published since the last successful sync
+func (uc *SyncRafflesUseCase) Execute(ctx context.Context) (models.RaffleSyncReport, error) {
+	var report models.RaffleSyncReport
+	startedAt := time.Now()
+
+	fromDate, err := uc.fromDate(ctx, startedAt)
+	if err != nil {
+		return report, err
+	}
+
+	raffles, results, err := uc.activeRafflesUseCase.Discover(ctx, fromDate)
+	if err != nil {
+		return report, err
+	}
+
+	created := make(map[int64]bool, len(raffles))
+	saved := make(map[int64]bool, len(raffles))
+	for _, r := range raffles {
+		isNew, err := uc.raffleRepo.Save(ctx, r)
+		if err != nil {
+			return report, err
+		}
+		saved[r.Id] = true
+		if isNew {
+			created[r.Id] = true
+			r.State = models.RaffleStateNew
+			report.New = append(report.New, r)
+		}
+	}
+
+	report.ResultsLinked, err = uc.linkResults(ctx, results)
+	if err != nil {
+		return report, err
+	}
+
+	// ended raffles are checked too, organizer could move the deadline
+	stored, err := uc.raffleRepo.GetByStates(
+		ctx,
+		models.RaffleStateNew,
+		models.RaffleStateActive,
+		models.RaffleStateEndingSoon,
+		models.RaffleStateEnded,
+	)
+	if err != nil {
+		return report, err
+	}
+
+	now := time.Now()
+	var open []models.Raffle
+	for _, r := range stored {
+		// new raffles stay new until the next sync
+		if created[r.Id] {
+			continue
+		}
+		next := raffle.NextState(r, now)
+		if next.IsOpen() && !saved[r.Id] {
+			open = append(open, r)
+		}
+		if next == r.State {
+			continue
+		}
+		if err := uc.raffleRepo.UpdateState(ctx, r.Id, next); err != nil {
+			return report, err
+		}
+		slog.Debug("Raffle state changed", "post_id", r.Id, "from", r.State, "to", next)
+		r.State = next
+		report.Changed = append(report.Changed, r)
+	}
+
+	if err := uc.refreshCounters(ctx, open); err != nil {
+		return report, err
+	}
+
+	// failed syncs don't move watermark, next one fetches the same window
+	if err := uc.watermarkRepo.Set(ctx, rafflesWatermark, startedAt); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// refreshCounters updates likes, comments and views of raffles
+// which are not in search window anymore, digest shows them
+func (uc *SyncRafflesUseCase) refreshCounters(ctx context.Context, raffles []models.Raffle) error {
+	refreshed, err := enrichRaffles(ctx, uc.postRepo, raffles, counterRefreshWorkers)
+	if err != nil {
+		return err
+	}
+	for _, r := range refreshed {
+		if err := uc.raffleRepo.UpdateCounters(ctx, r.Post); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (uc *SyncRafflesUseCase) fromDate(ctx context.Context, now time.Time) (time.Time, error) {
+	watermark, err := uc.watermarkRepo.Get(ctx, rafflesWatermark)
+	if errors.Is(err, domain.ErrWatermarkNotFound) {
+		return now.Add(-defaultSyncLookback), nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return watermark.Add(-syncOverlap), nil
+}
+
+// linkResults matches results posts with stored raffles
+// and closes their lifecycle
+func (uc *SyncRafflesUseCase) linkResults(ctx context.Context, results []models.Raffle) ([]models.Raffle, error) {
+	if len(results) == 0 {
+		return nil, nil
+	}
+
+	candidates, err := uc.raffleRepo.GetByStates(
+		ctx,
+		models.RaffleStateNew,
+		models.RaffleStateActive,
+		models.RaffleStateEndingSoon,
+		models.RaffleStateEnded,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var linked []models.Raffle
+	for _, resultsPost := range results {
+		_, err := uc.raffleRepo.GetByResultsPostId(ctx, resultsPost.Id)
+		if err == nil {
+			// already linked by previous sync
+			continue
+		}
+		if !errors.Is(err, domain.ErrRaffleNotFound) {
+			return linked, err
+		}
+
+		original, ok := raffle.MatchResults(resultsPost.Post, candidates)
+		if !ok {
+			slog.Debug("Results post without raffle", "post_id", resultsPost.Id, "author_id", resultsPost.Author.Id)
+			continue
+		}
+
+		original.Results = models.ResultsFromPost(resultsPost.Post)
+		if err := uc.raffleRepo.SaveResults(ctx, original.Id, original.Results); err != nil {
+			return linked, err
+		}
+		slog.Debug("Raffle results linked", "post_id", original.Id, "results_post_id", resultsPost.Id)
+		original.State = models.RaffleStateResultsPublished
+		linked = append(linked, original)
+
+		candidates = slices.DeleteFunc(candidates, func(r models.Raffle) bool {
+			return r.Id == original.Id
+		})
+	}
+
+	return linked, nil
+}
@@ -0,0 +1,49 @@
+package usecases
+
+import (
+	"context"
+	"dtf/game_draw/pkg/dtfapi/dtfapitest"
+	"testing"
+	"time"
+)
+
+func TestSyncRefreshesCounters(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+	env.server.AddUser(organizer)
+	session := env.addAccount(t, participant, participantTelegramId)
+
+	// older than the next sync window, so only refresh updates it
+	post := env.server.AddPost(dtfapitest.Post{
+		Title:    "Розыгрыш ключа Hades",
+		Date:     time.Now().Add(-3 * time.Hour),
+		AuthorId: organizer.Id,
+		Likes:    5,
+		Views:    100,
+		Blocks:   []dtfapitest.Block{dtfapitest.TextBlock("Для участия поставьте лайк.")},
+	})
+	if report := env.syncRaffles(t); len(report.New) != 1 {
+		t.Fatalf("synced %d raffles, want 1", len(report.New))
+	}
+
+	stored, err := env.raffleRepo.GetById(ctx, int64(post.Id))
+	if err != nil {
+		t.Fatalf("get raffle: %v", err)
+	}
+	if stored.Likes != 5 || stored.Views != 100 {
+		t.Errorf("counters = %d likes, %d views, want 5, 100", stored.Likes, stored.Views)
+	}
+
+	if err := env.postRepo.ReactToPost(ctx, session, stored.Post); err != nil {
+		t.Fatalf("react: %v", err)
+	}
+	env.syncRaffles(t)
+
+	stored, err = env.raffleRepo.GetById(ctx, int64(post.Id))
+	if err != nil {
+		t.Fatalf("get raffle: %v", err)
+	}
+	if stored.Likes != 6 {
+		t.Errorf("likes after sync = %d, want 6", stored.Likes)
+	}
+}
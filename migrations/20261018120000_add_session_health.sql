@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE user_sessions ADD COLUMN refresh_expiration TEXT;
+ALTER TABLE user_sessions ADD COLUMN status TEXT NOT NULL DEFAULT 'active';
+ALTER TABLE user_sessions ADD COLUMN last_refreshed_at TEXT;
+ALTER TABLE user_sessions ADD COLUMN last_error TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE user_sessions DROP COLUMN last_error;
+ALTER TABLE user_sessions DROP COLUMN last_refreshed_at;
+ALTER TABLE user_sessions DROP COLUMN status;
+ALTER TABLE user_sessions DROP COLUMN refresh_expiration;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE raffles (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  post_id INTEGER NOT NULL UNIQUE,
+  title TEXT NOT NULL,
+  uri TEXT NOT NULL,
+  text TEXT NOT NULL DEFAULT '',
+  author_id INTEGER NOT NULL DEFAULT 0,
+  author_name TEXT NOT NULL DEFAULT '',
+  subsite_id INTEGER NOT NULL DEFAULT 0,
+  subsite_name TEXT NOT NULL DEFAULT '',
+  published_at TEXT,
+  ends_at TEXT,
+  ends_at_confidence INTEGER NOT NULL DEFAULT 0,
+  ends_at_date_only INTEGER NOT NULL DEFAULT 0,
+  state TEXT NOT NULL DEFAULT 'new',
+  first_seen_at TEXT NOT NULL,
+  state_changed_at TEXT NOT NULL,
+  updated_at TEXT NOT NULL
+);
+
+CREATE INDEX raffles_state_idx ON raffles (state);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX raffles_state_idx;
+DROP TABLE raffles;
+-- +goose StatementEnd
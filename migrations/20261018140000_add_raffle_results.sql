@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE raffles ADD COLUMN results_post_id INTEGER;
+ALTER TABLE raffles ADD COLUMN results_title TEXT NOT NULL DEFAULT '';
+ALTER TABLE raffles ADD COLUMN results_uri TEXT NOT NULL DEFAULT '';
+ALTER TABLE raffles ADD COLUMN results_text TEXT NOT NULL DEFAULT '';
+ALTER TABLE raffles ADD COLUMN results_published_at TEXT;
+
+CREATE UNIQUE INDEX raffles_results_post_id_idx ON raffles (results_post_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX raffles_results_post_id_idx;
+ALTER TABLE raffles DROP COLUMN results_published_at;
+ALTER TABLE raffles DROP COLUMN results_text;
+ALTER TABLE raffles DROP COLUMN results_uri;
+ALTER TABLE raffles DROP COLUMN results_title;
+ALTER TABLE raffles DROP COLUMN results_post_id;
+-- +goose StatementEnd
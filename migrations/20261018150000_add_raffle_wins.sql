@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE raffle_wins (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  raffle_post_id INTEGER NOT NULL,
+  email TEXT NOT NULL,
+  dtf_user_id INTEGER NOT NULL,
+  fragment TEXT NOT NULL,
+  uri TEXT NOT NULL,
+  found_at TEXT NOT NULL,
+
+  UNIQUE (raffle_post_id, email)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE raffle_wins;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE raffle_deliveries (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  raffle_post_id INTEGER NOT NULL,
+  telegram_id INTEGER NOT NULL,
+  delivered_at TEXT NOT NULL,
+
+  UNIQUE (raffle_post_id, telegram_id)
+);
+
+CREATE INDEX raffle_deliveries_telegram_idx ON raffle_deliveries (telegram_id);
+
+CREATE TABLE sync_watermarks (
+  name TEXT PRIMARY KEY,
+  watermark TEXT NOT NULL,
+  updated_at TEXT NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE sync_watermarks;
+DROP INDEX raffle_deliveries_telegram_idx;
+DROP TABLE raffle_deliveries;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE source_rules (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  -- telegram id of the owner, 0 for admin rules
+  owner INTEGER NOT NULL DEFAULT 0,
+  kind TEXT NOT NULL,
+  source_id INTEGER NOT NULL,
+  mode TEXT NOT NULL,
+  created_at TEXT NOT NULL,
+
+  UNIQUE (owner, kind, source_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE source_rules;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE raffles ADD COLUMN author_created_at TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE raffles DROP COLUMN author_created_at;
+-- +goose StatementEnd
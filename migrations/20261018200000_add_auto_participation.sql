@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE user_sessions ADD COLUMN auto_participate INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE raffle_participations (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  raffle_post_id INTEGER NOT NULL,
+  email TEXT NOT NULL,
+  status TEXT NOT NULL,
+  liked INTEGER NOT NULL DEFAULT 0,
+  commented INTEGER NOT NULL DEFAULT 0,
+  comment_text TEXT NOT NULL DEFAULT '',
+  reason TEXT NOT NULL DEFAULT '',
+  created_at TEXT NOT NULL,
+
+  UNIQUE (raffle_post_id, email)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE raffle_participations;
+ALTER TABLE user_sessions DROP COLUMN auto_participate;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE raffles ADD COLUMN likes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE raffles ADD COLUMN comments INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE raffles ADD COLUMN views INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE raffles ADD COLUMN cover_url TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE raffles DROP COLUMN cover_url;
+ALTER TABLE raffles DROP COLUMN views;
+ALTER TABLE raffles DROP COLUMN comments;
+ALTER TABLE raffles DROP COLUMN likes;
+-- +goose StatementEnd
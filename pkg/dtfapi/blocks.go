@@ -0,0 +1,227 @@
+package dtfapi
+
+import (
+	"encoding/json"
+)
+
+// imageCdnUrl is the osnova storage for uploaded images
+const imageCdnUrl = "https://leonardo.osnova.io/"
+
+// embedServices are block types which contain content of another service
+var embedServices = map[string]struct{}{
+	"embed":     {},
+	"youtube":   {},
+	"telegram":  {},
+	"twitter":   {},
+	"vimeo":     {},
+	"vk":        {},
+	"twitch":    {},
+	"instagram": {},
+	"tiktok":    {},
+	"video":     {},
+	"audio":     {},
+}
+
+type mediaItem struct {
+	Title string `json:"title"`
+	Image struct {
+		Type string `json:"type"`
+		Data struct {
+			Uuid   string `json:"uuid"`
+			Width  int    `json:"width"`
+			Height int    `json:"height"`
+			Type   string `json:"type"`
+		} `json:"data"`
+	} `json:"image"`
+}
+
+func (mi mediaItem) toImage() DataImage {
+	return DataImage{
+		Uuid:    mi.Image.Data.Uuid,
+		Url:     imageCdnUrl + mi.Image.Data.Uuid + "/",
+		Width:   mi.Image.Data.Width,
+		Height:  mi.Image.Data.Height,
+		Format:  mi.Image.Data.Type,
+		Caption: mi.Title,
+	}
+}
+
+// mapPostBlock converts raw api block to typed DataBlock.
+// Unsupported blocks are returned as DataUnknown.
+func mapPostBlock(block PostBlock) (DataBlock, error) {
+	switch block.Type {
+	case "text":
+		var textBlock struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(block.Data, &textBlock); err != nil {
+			return nil, err
+		}
+		return DataText{
+			HtmlText: textBlock.Text,
+		}, nil
+
+	case "header":
+		var headerBlock struct {
+			Style string `json:"style"`
+			Text  string `json:"text"`
+		}
+		if err := json.Unmarshal(block.Data, &headerBlock); err != nil {
+			return nil, err
+		}
+		return DataHeader{
+			Style: headerBlock.Style,
+			Text:  headerBlock.Text,
+		}, nil
+
+	case "list":
+		var listBlock struct {
+			Items []string `json:"items"`
+			Type  string   `json:"type"`
+		}
+		if err := json.Unmarshal(block.Data, &listBlock); err != nil {
+			return nil, err
+		}
+		listType := ListUnordered
+		if ListType(listBlock.Type) == ListOrdered {
+			listType = ListOrdered
+		}
+		return DataList{
+			items:    listBlock.Items,
+			ListType: listType,
+		}, nil
+
+	case "media", "image":
+		var mediaBlock struct {
+			Items []mediaItem `json:"items"`
+		}
+		if err := json.Unmarshal(block.Data, &mediaBlock); err != nil {
+			return nil, err
+		}
+		if len(mediaBlock.Items) == 1 {
+			return mediaBlock.Items[0].toImage(), nil
+		}
+		images := make([]DataImage, 0, len(mediaBlock.Items))
+		for _, item := range mediaBlock.Items {
+			images = append(images, item.toImage())
+		}
+		return DataGallery{
+			Images: images,
+		}, nil
+
+	case "quote", "incut":
+		var quoteBlock struct {
+			Text     string `json:"text"`
+			Subline1 string `json:"subline1"`
+		}
+		if err := json.Unmarshal(block.Data, &quoteBlock); err != nil {
+			return nil, err
+		}
+		return DataQuote{
+			HtmlText: quoteBlock.Text,
+			Author:   quoteBlock.Subline1,
+		}, nil
+
+	case "link":
+		var linkBlock struct {
+			Link struct {
+				Data struct {
+					Url         string `json:"url"`
+					Title       string `json:"title"`
+					Description string `json:"description"`
+				} `json:"data"`
+			} `json:"link"`
+		}
+		if err := json.Unmarshal(block.Data, &linkBlock); err != nil {
+			return nil, err
+		}
+		return DataLink{
+			Url:         linkBlock.Link.Data.Url,
+			Title:       linkBlock.Link.Data.Title,
+			Description: linkBlock.Link.Data.Description,
+		}, nil
+
+	case "spoiler":
+		var spoilerBlock struct {
+			Title string `json:"title"`
+			Text  string `json:"text"`
+		}
+		if err := json.Unmarshal(block.Data, &spoilerBlock); err != nil {
+			return nil, err
+		}
+		return DataSpoiler{
+			Title:    spoilerBlock.Title,
+			HtmlText: spoilerBlock.Text,
+		}, nil
+
+	case "code":
+		var codeBlock struct {
+			Text string `json:"text"`
+			Lang string `json:"lang"`
+		}
+		if err := json.Unmarshal(block.Data, &codeBlock); err != nil {
+			return nil, err
+		}
+		return DataCode{
+			Text: codeBlock.Text,
+			Lang: codeBlock.Lang,
+		}, nil
+
+	case "delimiter":
+		return DataDelimiter{}, nil
+	}
+
+	if _, ok := embedServices[block.Type]; ok {
+		return mapEmbedBlock(block)
+	}
+
+	return DataUnknown{
+		BlockType: block.Type,
+		Raw:       block.Data,
+	}, nil
+}
+
+// mapEmbedBlock parses embeds. Their data looks like
+// {"<service>": {"type": "<service>", "data": {"url": "..."}}}
+// If embed can't be recognized, block is kept as DataUnknown.
+func mapEmbedBlock(block PostBlock) (DataBlock, error) {
+	var embedBlock map[string]json.RawMessage
+	if err := json.Unmarshal(block.Data, &embedBlock); err != nil {
+		return nil, err
+	}
+
+	// key named after the block type goes first, others are fallback
+	keys := make([]string, 0, len(embedBlock))
+	if _, ok := embedBlock[block.Type]; ok {
+		keys = append(keys, block.Type)
+	}
+	for key := range embedBlock {
+		if key != block.Type {
+			keys = append(keys, key)
+		}
+	}
+
+	for _, service := range keys {
+		var embedData struct {
+			Type string `json:"type"`
+			Data struct {
+				Url   string `json:"url"`
+				Title string `json:"title"`
+			} `json:"data"`
+		}
+		// not every key is an embed object, skipping others
+		if err := json.Unmarshal(embedBlock[service], &embedData); err != nil || embedData.Type == "" {
+			continue
+		}
+		return DataEmbed{
+			Service: service,
+			Url:     embedData.Data.Url,
+			Title:   embedData.Data.Title,
+		}, nil
+	}
+
+	return DataUnknown{
+		BlockType: block.Type,
+		Raw:       block.Data,
+	}, nil
+}
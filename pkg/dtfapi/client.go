@@ -3,6 +3,7 @@ package dtfapi
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"math/rand/v2"
 
 	"golang.org/x/time/rate"
@@ -10,8 +11,11 @@ import (
 )
 
 type apiClient struct {
-	client  *resty.Client
-	limiter *rate.Limiter
+	client      *resty.Client
+	limiter     *rate.Limiter
+	retryPolicy RetryPolicy
+	logger      *slog.Logger
+	userAgents  []string
 }
 
 var userAgents = []string{
@@ -27,18 +31,42 @@ var userAgents = []string{
 	"Mozilla/5.0 (Linux; Android 10; Infinix X683 Build/QP1A.190711.020; wv) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/89.0.4389.86 Mobile Safari/537.36",
 }
 
-func NewClient(ctx context.Context) *apiClient {
-	limiter := rate.NewLimiter(rate.Limit(1/3.0), 3) // we allow only 3 request per second
-	restClient := resty.New()
+func NewClient(ctx context.Context, opts ...Option) *apiClient {
+	options := applyOptions(opts)
+
+	limiter := rate.NewLimiter(options.rateLimit, options.burst)
+	restClient := resty.New().
+		SetLogger(restyLogger{logger: options.logger}).
+		SetTimeout(options.timeout)
+	if options.baseUrl != "" {
+		restClient.SetBaseURL(options.baseUrl)
+	}
+	if options.transport != nil {
+		restClient.SetTransport(options.transport)
+	}
 
 	result := &apiClient{
-		client:  restClient,
-		limiter: limiter,
+		client:     restClient,
+		limiter:    limiter,
+		logger:     options.logger,
+		userAgents: options.userAgents,
 	}
 	result.initClientMiddlewares(ctx)
+	result.initRetries()
+	result.SetRetryPolicy(options.retryPolicy)
 	return result
 }
 
+// SetRetryPolicy replaces retry policy of the client.
+// Should be called before the client is used.
+func (c *apiClient) SetRetryPolicy(policy RetryPolicy) {
+	c.retryPolicy = policy
+	c.client.
+		SetRetryCount(policy.MaxRetries).
+		SetRetryWaitTime(policy.MinWait).
+		SetRetryMaxWaitTime(policy.MaxWait)
+}
+
 func (c apiClient) Client() *resty.Client {
 	return c.client
 }
@@ -52,8 +80,8 @@ func (c *apiClient) Close() error {
 }
 
 func (c *apiClient) initClientMiddlewares(ctx context.Context) {
-	c.client.AddRequestMiddleware(func(c *resty.Client, r *resty.Request) error {
-		userAgent := getRandomUserAgent()
+	c.client.AddRequestMiddleware(func(_ *resty.Client, r *resty.Request) error {
+		userAgent := c.getRandomUserAgent()
 		r.SetHeader("User-agent", userAgent)
 		return nil
 	})
@@ -71,7 +99,19 @@ func (c *apiClient) initClientMiddlewares(ctx context.Context) {
 	})
 }
 
-func getRandomUserAgent() string {
-	randomIndex := rand.IntN(len(userAgents))
-	return userAgents[randomIndex]
+func (c *apiClient) initRetries() {
+	c.client.
+		// resty's default conditions retry everything,
+		// our policy decides itself what can be retried
+		SetRetryDefaultConditions(false).
+		SetAllowNonIdempotentRetry(true).
+		AddRetryConditions(func(res *resty.Response, err error) bool {
+			return c.retryPolicy.shouldRetry(res, err)
+		}).
+		AddRetryHooks(c.logRetry)
+}
+
+func (c *apiClient) getRandomUserAgent() string {
+	randomIndex := rand.IntN(len(c.userAgents))
+	return c.userAgents[randomIndex]
 }
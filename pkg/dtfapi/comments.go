@@ -0,0 +1,166 @@
+package dtfapi
+
+import (
+	"context"
+	"iter"
+	"strconv"
+	"time"
+)
+
+// maxCommentPages protects from endless pagination, same as maxSearchPages
+const maxCommentPages = 50
+
+type CommentResponse struct {
+	Id     int `json:"id"`
+	Author struct {
+		Id   int    `json:"id"`
+		Name string `json:"name"`
+		Url  string `json:"url"`
+	} `json:"author"`
+	Date    int64  `json:"date"`
+	Text    string `json:"text"`
+	ReplyTo int    `json:"replyTo"`
+	Level   int    `json:"level"`
+	Likes   struct {
+		Counter int `json:"counter"`
+	} `json:"likes"`
+	IsRemoved bool `json:"isRemoved"`
+}
+
+type CommentsResponse struct {
+	Result struct {
+		Items  []CommentResponse `json:"items"`
+		LastId int               `json:"lastId"`
+	} `json:"result"`
+}
+
+// GetCommentsPage returns one page of post's comments sorted by date.
+// Pass lastId = 0 to get the first page.
+func (c *DtfService) GetCommentsPage(
+	ctx context.Context,
+	postId int,
+	lastId int,
+) (CommentsPage, error) {
+	var apiResponse CommentsResponse
+	var apiError DtfErrorV2
+
+	params := map[string]string{
+		"id":       strconv.Itoa(postId),
+		"sorting":  "date",
+		"markdown": "false",
+	}
+	if lastId != 0 {
+		params["lastId"] = strconv.Itoa(lastId)
+	}
+
+	resp, err := c.client.
+		R().
+		SetContext(ctx).
+		SetQueryParams(params).
+		SetResult(&apiResponse).
+		SetError(&apiError).
+		Get("/v2.4/comments")
+	if err != nil || resp.IsError() {
+		return CommentsPage{}, requestError(resp, err, apiError)
+	}
+
+	page := CommentsPage{
+		Comments: make([]Comment, 0, len(apiResponse.Result.Items)),
+	}
+	for _, item := range apiResponse.Result.Items {
+		if item.IsRemoved {
+			continue
+		}
+		page.Comments = append(page.Comments, mapCommentResponseToComment(postId, &item))
+	}
+
+	next := apiResponse.Result.LastId
+	if len(apiResponse.Result.Items) == 0 || next == 0 || next == lastId {
+		page.Exhausted = true
+	} else {
+		page.LastId = next
+	}
+
+	return page, nil
+}
+
+// GetCommentsIter iterates over all comments of the post, requesting pages lazily.
+// Iteration stops after the first error.
+func (c *DtfService) GetCommentsIter(ctx context.Context, postId int) iter.Seq2[Comment, error] {
+	return func(yield func(Comment, error) bool) {
+		lastId := 0
+		for range maxCommentPages {
+			page, err := c.GetCommentsPage(ctx, postId, lastId)
+			if err != nil {
+				yield(Comment{}, err)
+				return
+			}
+
+			for _, comment := range page.Comments {
+				if !yield(comment, nil) {
+					return
+				}
+			}
+
+			if page.Exhausted {
+				return
+			}
+			lastId = page.LastId
+		}
+
+		c.logger.Warn("comment pages limit reached", "post_id", postId, "limit", maxCommentPages)
+	}
+}
+
+// GetComments returns all comments of the post.
+// Use ReplyTo field or BuildCommentThreads to restore the thread structure.
+func (c *DtfService) GetComments(ctx context.Context, postId int) ([]Comment, error) {
+	var result []Comment
+	for comment, err := range c.GetCommentsIter(ctx, postId) {
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, comment)
+	}
+
+	return result, nil
+}
+
+// BuildCommentThreads groups flat comments into threads.
+// Replies to missing (removed) comments become roots.
+func BuildCommentThreads(comments []Comment) []*CommentThread {
+	nodes := make(map[int]*CommentThread, len(comments))
+	for _, comment := range comments {
+		nodes[comment.Id] = &CommentThread{Comment: comment}
+	}
+
+	var roots []*CommentThread
+	for _, comment := range comments {
+		node := nodes[comment.Id]
+		parent, ok := nodes[comment.ReplyTo]
+		if comment.ReplyTo == 0 || !ok {
+			roots = append(roots, node)
+			continue
+		}
+		parent.Replies = append(parent.Replies, node)
+	}
+
+	return roots
+}
+
+func mapCommentResponseToComment(postId int, response *CommentResponse) Comment {
+	return Comment{
+		Id:     response.Id,
+		PostId: postId,
+		Author: UserInfo{
+			Id:   response.Author.Id,
+			Url:  response.Author.Url,
+			Name: response.Author.Name,
+		},
+		HtmlText: response.Text,
+		ReplyTo:  response.ReplyTo,
+		Level:    response.Level,
+		Date:     time.Unix(response.Date, 0),
+		Likes:    response.Likes.Counter,
+	}
+}
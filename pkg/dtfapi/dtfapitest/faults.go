@@ -0,0 +1,92 @@
+package dtfapitest
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Fault describes broken behaviour of the server.
+// Faults are checked in order of injection, the first matching one is applied.
+type Fault struct {
+	Route string // e.g. "GET /v2.8/search/posts", empty matches every route
+	Times int    // how many requests are affected, 0 means unlimited
+
+	Latency    time.Duration // delay before response
+	Status     int           // respond with this status, 0 means normal response
+	RetryAfter int           // Retry-After header in seconds, used with Status
+	Malformed  bool          // respond with broken json and 200 status
+}
+
+func (s *Server) InjectFault(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &fault)
+}
+
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+}
+
+// takeFault returns fault for the route and decrements its counter
+func (s *Server) takeFault(route string) (Fault, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, fault := range s.faults {
+		if fault.Route != "" && fault.Route != route {
+			continue
+		}
+		result := *fault
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return result, true
+	}
+
+	return Fault{}, false
+}
+
+// withFaults counts requests and applies injected faults
+func (s *Server) withFaults(route string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[route]++
+		s.mu.Unlock()
+
+		fault, ok := s.takeFault(route)
+		if !ok {
+			next(w, r)
+			return
+		}
+
+		if fault.Latency > 0 {
+			select {
+			case <-time.After(fault.Latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		switch {
+		case fault.Malformed:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"result": {"items": [`))
+		case fault.Status != 0:
+			if fault.RetryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(fault.RetryAfter))
+			}
+			writeJSON(w, fault.Status, errorV2(http.StatusText(fault.Status), fault.Status))
+		default:
+			next(w, r)
+		}
+	}
+}
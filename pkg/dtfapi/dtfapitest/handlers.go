@@ -0,0 +1,382 @@
+package dtfapitest
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	handle := func(route string, handler http.HandlerFunc) {
+		mux.HandleFunc(route, s.withFaults(route, handler))
+	}
+	handle("POST /v3.0/auth/email/login", s.handleEmailLogin)
+	handle("POST /v3.0/auth/refresh", s.handleRefresh)
+	handle("GET /v2.1/subsite/me", s.handleSelf)
+	handle("GET /v2.10/content", s.handleContent)
+	handle("GET /v2.8/search/posts", s.handleSearch)
+	handle("POST /v2.5/content/{post_id}/react", s.handleReact)
+	handle("POST /v2.4/comment/add", s.handleAddComment)
+	handle("GET /v2.4/comments", s.handleComments)
+
+	return mux
+}
+
+func errorV2(message string, code int) map[string]any {
+	return map[string]any{
+		"message": message,
+		"error":   map[string]int{"code": code},
+	}
+}
+
+func errorV3(message string, code int) map[string]any {
+	return map[string]any{
+		"message": message,
+		"code":    code,
+	}
+}
+
+func tokensResponse(session *session) map[string]any {
+	return map[string]any{
+		"message": "",
+		"data": map[string]any{
+			"accessToken":         session.access,
+			"accessExpTimestamp":  session.accessExp.Unix(),
+			"refreshToken":        session.refresh,
+			"refreshExpTimestamp": session.refreshExp.Unix(),
+		},
+	}
+}
+
+func (s *Server) handleEmailLogin(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseMultipartForm(1 << 20)
+	email := r.FormValue("email")
+	password := r.FormValue("password")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, user := range s.users {
+		if user.Email == email && user.Password == password {
+			writeJSON(w, http.StatusOK, tokensResponse(s.issueSession(user.Id)))
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusBadRequest, errorV3("Invalid login or password", 104))
+}
+
+func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseMultipartForm(1 << 20)
+	token := r.FormValue("token")
+	if token == "" {
+		// real api responds with 200 here
+		writeJSON(w, http.StatusOK, map[string]any{"message": "Refresh token is missing"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, session := range s.sessions {
+		if session.refresh != token {
+			continue
+		}
+		// previous pair is disposed on refresh
+		s.removeSession(session)
+		if s.now().After(session.refreshExp) {
+			break
+		}
+		writeJSON(w, http.StatusOK, tokensResponse(s.issueSession(session.userId)))
+		return
+	}
+
+	writeJSON(w, http.StatusUnauthorized, errorV3("Refresh token is invalid", 401))
+}
+
+// authorize returns user of the request or writes 401
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) (*User, bool) {
+	token := strings.TrimPrefix(r.Header.Get("Jwtauthorization"), "Bearer ")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, session := range s.sessions {
+		if token == "" || session.access != token {
+			continue
+		}
+		if s.now().After(session.accessExp) {
+			break
+		}
+		if user, ok := s.users[session.userId]; ok {
+			return user, true
+		}
+	}
+
+	writeJSON(w, http.StatusUnauthorized, errorV2("Unauthorized", 401))
+	return nil, false
+}
+
+func (s *Server) handleSelf(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.authorize(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"message": "",
+		"result": map[string]any{
+			"id":   user.Id,
+			"url":  user.Url,
+			"name": user.Name,
+		},
+	})
+}
+
+func (s *Server) handleContent(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.URL.Query().Get("id"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	post, ok := s.posts[id]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, errorV2("Content not found", 404))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"result": s.postJSON(post)})
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := strings.ToLower(query.Get("q"))
+	dateFrom, _ := strconv.ParseInt(query.Get("dateFrom"), 10, 64)
+	lastId, _ := strconv.Atoi(query.Get("lastId"))
+	lastSortingValue, _ := strconv.ParseInt(query.Get("lastSortingValue"), 10, 64)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var found []*Post
+	for _, post := range s.posts {
+		if !strings.Contains(strings.ToLower(post.Title), q) || post.Date.Unix() < dateFrom {
+			continue
+		}
+		found = append(found, post)
+	}
+	// newest first, same as sorting=date
+	slices.SortFunc(found, func(a, b *Post) int {
+		if c := b.Date.Compare(a.Date); c != 0 {
+			return c
+		}
+		return b.Id - a.Id
+	})
+
+	start := 0
+	if lastId != 0 {
+		start = len(found)
+		for i, post := range found {
+			date := post.Date.Unix()
+			if date < lastSortingValue || (date == lastSortingValue && post.Id < lastId) {
+				start = i
+				break
+			}
+		}
+	}
+	end := min(start+s.pageSize, len(found))
+
+	items := make([]map[string]any, 0, end-start)
+	for _, post := range found[start:end] {
+		items = append(items, map[string]any{"type": "entry", "data": s.postJSON(post)})
+	}
+	result := map[string]any{"items": items}
+	if end > start {
+		last := found[end-1]
+		result["lastId"] = last.Id
+		result["lastSortingValue"] = last.Date.Unix()
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"result": result})
+}
+
+func (s *Server) handleReact(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.authorize(w, r)
+	if !ok {
+		return
+	}
+	postId, _ := strconv.Atoi(r.PathValue("post_id"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.posts[postId]; !ok {
+		writeJSON(w, http.StatusNotFound, errorV2("Content not found", 404))
+		return
+	}
+	if s.reactions[postId] == nil {
+		s.reactions[postId] = make(map[int]bool)
+	}
+	s.reactions[postId][user.Id] = true
+
+	writeJSON(w, http.StatusOK, map[string]any{"message": "", "result": map[string]any{}})
+}
+
+func (s *Server) handleAddComment(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.authorize(w, r)
+	if !ok {
+		return
+	}
+	_ = r.ParseMultipartForm(1 << 20)
+	postId, _ := strconv.Atoi(r.FormValue("id"))
+	replyTo, _ := strconv.Atoi(r.FormValue("reply_to"))
+	text := r.FormValue("text")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.posts[postId]; !ok {
+		writeJSON(w, http.StatusNotFound, errorV2("Content not found", 404))
+		return
+	}
+	if strings.TrimSpace(text) == "" {
+		writeJSON(w, http.StatusBadRequest, errorV2("Comment is empty", 400))
+		return
+	}
+
+	comment := s.addComment(Comment{
+		PostId:   postId,
+		AuthorId: user.Id,
+		Text:     text,
+		ReplyTo:  replyTo,
+	})
+	writeJSON(w, http.StatusOK, map[string]any{"message": "", "result": s.commentJSON(comment)})
+}
+
+func (s *Server) handleComments(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	postId, _ := strconv.Atoi(query.Get("id"))
+	lastId, _ := strconv.Atoi(query.Get("lastId"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.posts[postId]; !ok {
+		writeJSON(w, http.StatusNotFound, errorV2("Content not found", 404))
+		return
+	}
+
+	var found []Comment
+	for _, comment := range s.comments {
+		if comment.PostId == postId {
+			found = append(found, comment)
+		}
+	}
+
+	start := 0
+	if lastId != 0 {
+		start = len(found)
+		for i, comment := range found {
+			if comment.Id == lastId {
+				start = i + 1
+				break
+			}
+		}
+	}
+	end := min(start+s.pageSize, len(found))
+
+	items := make([]map[string]any, 0, end-start)
+	for _, comment := range found[start:end] {
+		items = append(items, s.commentJSON(comment))
+	}
+	result := map[string]any{"items": items}
+	if end > start {
+		result["lastId"] = found[end-1].Id
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"result": result})
+}
+
+// postJSON must be called under lock
+func (s *Server) postJSON(post *Post) map[string]any {
+	blocks := make([]map[string]any, 0, len(post.Blocks))
+	for _, block := range post.Blocks {
+		data, _ := json.Marshal(block.Data)
+		blocks = append(blocks, map[string]any{
+			"type":   block.Type,
+			"hidden": false,
+			"cover":  block.Cover,
+			"data":   json.RawMessage(data),
+		})
+	}
+
+	subsiteId := post.SubsiteId
+	if subsiteId == 0 {
+		subsiteId = post.AuthorId
+	}
+	comments := 0
+	for _, comment := range s.comments {
+		if comment.PostId == post.Id {
+			comments++
+		}
+	}
+
+	return map[string]any{
+		"id":       post.Id,
+		"date":     post.Date.Unix(),
+		"title":    post.Title,
+		"url":      post.Url,
+		"blocks":   blocks,
+		"repostId": post.RepostId,
+		"author":   s.userJSON(post.AuthorId),
+		"subsite":  s.userJSON(subsiteId),
+		"likes":    map[string]int{"counter": post.Likes + len(s.reactions[post.Id])},
+		"counters": map[string]int{
+			"comments": comments,
+			"views":    post.Views,
+			"reposts":  post.Reposts,
+		},
+	}
+}
+
+// commentJSON must be called under lock
+func (s *Server) commentJSON(comment Comment) map[string]any {
+	level := 0
+	for parentId := comment.ReplyTo; parentId != 0; level++ {
+		next := 0
+		for _, parent := range s.comments {
+			if parent.Id == parentId {
+				next = parent.ReplyTo
+				break
+			}
+		}
+		parentId = next
+	}
+
+	return map[string]any{
+		"id":      comment.Id,
+		"author":  s.userJSON(comment.AuthorId),
+		"date":    comment.Date.Unix(),
+		"text":    comment.Text,
+		"replyTo": comment.ReplyTo,
+		"level":   level,
+		"likes":   map[string]int{"counter": comment.Likes},
+	}
+}
+
+// userJSON must be called under lock
+func (s *Server) userJSON(userId int) map[string]any {
+	user, ok := s.users[userId]
+	if !ok {
+		return map[string]any{"id": userId}
+	}
+	return map[string]any{
+		"id":   user.Id,
+		"name": user.Name,
+		"url":  user.Url,
+	}
+}
@@ -0,0 +1,344 @@
+package dtfapitest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"dtf/game_draw/pkg/dtfapi"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+var ErrFixtureNotFound = errors.New("fixture not found")
+
+type RecorderMode int
+
+const (
+	// ModeReplay serves responses from fixture files, network is never used
+	ModeReplay RecorderMode = iota
+	// ModeRecord sends requests to real api and saves redacted responses
+	ModeRecord
+)
+
+// redactedKeys are json keys which values are never written to fixtures
+var redactedKeys = map[string]struct{}{
+	"accesstoken":  {},
+	"refreshtoken": {},
+	"token":        {},
+	"email":        {},
+	"password":     {},
+}
+
+// keptHeaders are response headers stored in fixtures, others are dropped
+var keptHeaders = []string{"Content-Type", "Retry-After"}
+
+var emailRegexp = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+const redactedValue = "REDACTED"
+
+type fixtureResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+	RawBody string            `json:"rawBody,omitempty"` // used if body is not a json
+}
+
+type fixture struct {
+	Method string `json:"method"`
+	Url    string `json:"url"`
+	// responses for the same request in order they were recorded,
+	// the last one is repeated during replay
+	Responses []fixtureResponse `json:"responses"`
+}
+
+// Recorder is http.RoundTripper which records api responses into fixture files
+// and replays them. Pass it to dtfapi.NewClient via dtfapi.WithHTTPTransport.
+// Tokens, passwords and emails are redacted before saving.
+type Recorder struct {
+	mode          RecorderMode
+	dir           string
+	transport     http.RoundTripper
+	ignoredParams map[string]struct{}
+
+	mu       sync.Mutex
+	fixtures map[string]*fixture
+	replayed map[string]int // key -> responses served
+}
+
+type RecorderOption func(*Recorder)
+
+// WithRecorderTransport sets transport used in ModeRecord.
+// Default is http.DefaultTransport.
+func WithRecorderTransport(transport http.RoundTripper) RecorderOption {
+	return func(r *Recorder) {
+		r.transport = transport
+	}
+}
+
+// WithIgnoredParams excludes query params from fixture matching.
+// By default "dateFrom" is ignored, because it depends on the current time.
+func WithIgnoredParams(params ...string) RecorderOption {
+	return func(r *Recorder) {
+		r.ignoredParams = make(map[string]struct{}, len(params))
+		for _, param := range params {
+			r.ignoredParams[param] = struct{}{}
+		}
+	}
+}
+
+func NewRecorder(dir string, mode RecorderMode, opts ...RecorderOption) *Recorder {
+	r := &Recorder{
+		mode:          mode,
+		dir:           dir,
+		transport:     http.DefaultTransport,
+		ignoredParams: map[string]struct{}{"dateFrom": {}},
+		fixtures:      make(map[string]*fixture),
+		replayed:      make(map[string]int),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// ClientOptions returns dtfapi options which route requests through the recorder.
+// Rate limit is disabled in ModeReplay.
+func (r *Recorder) ClientOptions() []dtfapi.Option {
+	opts := []dtfapi.Option{dtfapi.WithHTTPTransport(r)}
+	if r.mode == ModeReplay {
+		opts = append(opts, dtfapi.WithRateLimit(rate.Inf, 1))
+	}
+	return opts
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, name := r.fixtureKey(req)
+
+	if r.mode == ModeReplay {
+		return r.replay(req, key, name)
+	}
+	return r.record(req, key, name)
+}
+
+func (r *Recorder) replay(req *http.Request, key, name string) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, err := r.loadFixture(key, name)
+	if err != nil {
+		return nil, err
+	}
+
+	i := min(r.replayed[key], len(f.Responses)-1)
+	r.replayed[key]++
+	stored := f.Responses[i]
+
+	body := []byte(stored.Body)
+	if stored.RawBody != "" {
+		body = []byte(stored.RawBody)
+	}
+	header := make(http.Header, len(stored.Headers))
+	for k, v := range stored.Headers {
+		header.Set(k, v)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", stored.Status, http.StatusText(stored.Status)),
+		StatusCode:    stored.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func (r *Recorder) record(req *http.Request, key, name string) (*http.Response, error) {
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	// original body goes back to the caller untouched
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	stored := fixtureResponse{
+		Status:  resp.StatusCode,
+		Headers: make(map[string]string),
+	}
+	for _, h := range keptHeaders {
+		if v := resp.Header.Get(h); v != "" {
+			stored.Headers[h] = v
+		}
+	}
+	if redacted, ok := redactJSON(body); ok {
+		stored.Body = redacted
+	} else {
+		stored.RawBody = emailRegexp.ReplaceAllString(string(body), redactedValue)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, ok := r.fixtures[key]
+	if !ok {
+		f = &fixture{
+			Method: req.Method,
+			Url:    redactURL(req.URL),
+		}
+		r.fixtures[key] = f
+	}
+	f.Responses = append(f.Responses, stored)
+
+	if err := r.saveFixture(f, name); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// loadFixture must be called under lock
+func (r *Recorder) loadFixture(key, name string) (*fixture, error) {
+	if f, ok := r.fixtures[key]; ok {
+		return f, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(r.dir, name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s (%s)", ErrFixtureNotFound, key, name)
+		}
+		return nil, err
+	}
+
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("fixture %s: %w", name, err)
+	}
+	if len(f.Responses) == 0 {
+		return nil, fmt.Errorf("%w: %s has no responses", ErrFixtureNotFound, name)
+	}
+
+	r.fixtures[key] = &f
+	return &f, nil
+}
+
+// saveFixture must be called under lock
+func (r *Recorder) saveFixture(f *fixture, name string) error {
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return err
+	}
+
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(f); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(r.dir, name), data.Bytes(), 0o644)
+}
+
+// fixtureKey builds matching key from method, path and query.
+// File name is readable part of the key plus its short hash.
+func (r *Recorder) fixtureKey(req *http.Request) (string, string) {
+	query := req.URL.Query()
+	params := make([]string, 0, len(query))
+	for param := range query {
+		if _, ignored := r.ignoredParams[param]; ignored {
+			continue
+		}
+		params = append(params, param)
+	}
+	slices.Sort(params)
+
+	var b strings.Builder
+	b.WriteString(req.Method + " " + req.URL.Path)
+	for i, param := range params {
+		if i == 0 {
+			b.WriteByte('?')
+		} else {
+			b.WriteByte('&')
+		}
+		b.WriteString(param + "=" + strings.Join(query[param], ","))
+	}
+	key := b.String()
+
+	hash := sha256.Sum256([]byte(key))
+	readable := strings.Trim(strings.NewReplacer("/", "_", ".", "-").Replace(req.URL.Path), "_")
+	name := fmt.Sprintf("%s_%s_%s.json", req.Method, readable, hex.EncodeToString(hash[:4]))
+
+	return key, name
+}
+
+func redactURL(u *url.URL) string {
+	redacted := *u
+	query := redacted.Query()
+	for param := range query {
+		if _, ok := redactedKeys[strings.ToLower(param)]; ok {
+			query.Set(param, redactedValue)
+		}
+	}
+	redacted.RawQuery = query.Encode()
+	redacted.User = nil
+	return redacted.String()
+}
+
+// redactJSON replaces secrets in json body.
+// Returns false if body is not a json.
+func redactJSON(body []byte) (json.RawMessage, bool) {
+	// numbers are kept as is, ids and timestamps must not become floats
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, false
+	}
+
+	redacted, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return nil, false
+	}
+	return redacted, true
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, inner := range v {
+			if _, ok := redactedKeys[strings.ToLower(key)]; ok {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = redactValue(inner)
+		}
+		return v
+	case []any:
+		for i, inner := range v {
+			v[i] = redactValue(inner)
+		}
+		return v
+	case string:
+		return emailRegexp.ReplaceAllString(v, redactedValue)
+	}
+	return value
+}
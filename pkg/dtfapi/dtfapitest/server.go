@@ -0,0 +1,315 @@
+// dtfapitest provides in-process fake of DTF api for tests and local runs.
+// It keeps users, tokens, posts and comments in memory
+// and can inject faults: latency, 429, 5xx and malformed json.
+package dtfapitest
+
+import (
+	"crypto/rand"
+	"dtf/game_draw/pkg/dtfapi"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultAccessTTL  = time.Hour
+	defaultRefreshTTL = 30 * 24 * time.Hour
+	defaultPageSize   = 10
+)
+
+type User struct {
+	Id       int
+	Name     string
+	Url      string
+	Email    string
+	Password string
+}
+
+type Block struct {
+	Type  string
+	Data  any // marshalled as block's data
+	Cover bool
+}
+
+func TextBlock(html string) Block {
+	return Block{Type: "text", Data: map[string]string{"text": html}}
+}
+
+func HeaderBlock(text string) Block {
+	return Block{Type: "header", Data: map[string]string{"text": text, "style": "h2"}}
+}
+
+// ImageBlock is a media block with single image, uuid is osnova storage id
+func ImageBlock(uuid string, cover bool) Block {
+	return Block{
+		Type: "media",
+		Data: map[string]any{
+			"items": []map[string]any{{
+				"title": "",
+				"image": map[string]any{
+					"type": "image",
+					"data": map[string]any{"uuid": uuid, "width": 800, "height": 600, "type": "jpg"},
+				},
+			}},
+		},
+		Cover: cover,
+	}
+}
+
+func ListBlock(ordered bool, items ...string) Block {
+	listType := "UL"
+	if ordered {
+		listType = "OL"
+	}
+	return Block{Type: "list", Data: map[string]any{"items": items, "type": listType}}
+}
+
+type Post struct {
+	Id        int
+	Date      time.Time
+	Title     string
+	Url       string
+	Blocks    []Block
+	RepostId  *int
+	AuthorId  int
+	SubsiteId int // zero means author's own blog
+	Likes     int
+	Views     int
+	Reposts   int
+}
+
+type Comment struct {
+	Id       int
+	PostId   int
+	AuthorId int
+	Text     string
+	ReplyTo  int
+	Date     time.Time
+	Likes    int
+}
+
+type session struct {
+	userId     int
+	access     string
+	accessExp  time.Time
+	refresh    string
+	refreshExp time.Time
+}
+
+// Server is a fake DTF api. Create it with NewServer and Close after use.
+type Server struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	now        func() time.Time
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	pageSize   int
+
+	users     map[int]*User
+	posts     map[int]*Post
+	comments  []Comment
+	reactions map[int]map[int]bool // post id -> user id
+	sessions  []*session
+	faults    []*Fault
+	requests  map[string]int // path pattern -> requests count
+	lastId    int
+}
+
+type Option func(*Server)
+
+// WithClock replaces time.Now, useful to check token expiration
+func WithClock(now func() time.Time) Option {
+	return func(s *Server) {
+		s.now = now
+	}
+}
+
+func WithTokenTTL(access, refresh time.Duration) Option {
+	return func(s *Server) {
+		s.accessTTL = access
+		s.refreshTTL = refresh
+	}
+}
+
+// WithPageSize sets how many items are returned by search and comments per page
+func WithPageSize(size int) Option {
+	return func(s *Server) {
+		s.pageSize = size
+	}
+}
+
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		now:        time.Now,
+		accessTTL:  defaultAccessTTL,
+		refreshTTL: defaultRefreshTTL,
+		pageSize:   defaultPageSize,
+		users:      make(map[int]*User),
+		posts:      make(map[int]*Post),
+		reactions:  make(map[int]map[int]bool),
+		requests:   make(map[string]int),
+		lastId:     1000,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.Server = httptest.NewServer(s.routes())
+	return s
+}
+
+// ClientOptions returns dtfapi options pointing to the server,
+// without rate limits and with short retry waits.
+func (s *Server) ClientOptions() []dtfapi.Option {
+	return []dtfapi.Option{
+		dtfapi.WithBaseURL(s.URL),
+		dtfapi.WithRateLimit(rate.Inf, 1),
+		dtfapi.WithRetryPolicy(dtfapi.RetryPolicy{
+			MaxRetries:    3,
+			MinWait:       time.Millisecond,
+			MaxWait:       10 * time.Millisecond,
+			MaxRetryAfter: time.Second,
+		}),
+	}
+}
+
+// AddUser seeds a user. Zero Id is replaced with generated one.
+func (s *Server) AddUser(user User) User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user.Id == 0 {
+		user.Id = s.nextId()
+	}
+	s.users[user.Id] = &user
+	return user
+}
+
+// AddPost seeds a post. Zero Id and Date are replaced with generated ones.
+func (s *Server) AddPost(post Post) Post {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if post.Id == 0 {
+		post.Id = s.nextId()
+	}
+	if post.Date.IsZero() {
+		post.Date = s.now()
+	}
+	s.posts[post.Id] = &post
+	return post
+}
+
+// AddComment seeds a comment. Zero Id and Date are replaced with generated ones.
+func (s *Server) AddComment(comment Comment) Comment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addComment(comment)
+}
+
+func (s *Server) addComment(comment Comment) Comment {
+	if comment.Id == 0 {
+		comment.Id = s.nextId()
+	}
+	if comment.Date.IsZero() {
+		comment.Date = s.now()
+	}
+	s.comments = append(s.comments, comment)
+	return comment
+}
+
+// Comments returns all comments of the post, including ones posted via api
+func (s *Server) Comments(postId int) []Comment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []Comment
+	for _, comment := range s.comments {
+		if comment.PostId == postId {
+			result = append(result, comment)
+		}
+	}
+	return result
+}
+
+// Reacted checks if user reacted to the post
+func (s *Server) Reacted(postId, userId int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.reactions[postId][userId]
+}
+
+// ExpireAccessTokens makes every issued access token expired,
+// refresh tokens are still valid.
+func (s *Server) ExpireAccessTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, session := range s.sessions {
+		session.accessExp = s.now().Add(-time.Second)
+	}
+}
+
+// RevokeSessions makes every issued token invalid
+func (s *Server) RevokeSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions = nil
+}
+
+// RequestCount returns how many requests were received by route,
+// e.g. "GET /v2.8/search/posts".
+func (s *Server) RequestCount(route string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests[route]
+}
+
+func (s *Server) nextId() int {
+	s.lastId++
+	return s.lastId
+}
+
+func (s *Server) issueSession(userId int) *session {
+	now := s.now()
+	session := &session{
+		userId:     userId,
+		access:     randomToken(),
+		accessExp:  now.Add(s.accessTTL),
+		refresh:    randomToken(),
+		refreshExp: now.Add(s.refreshTTL),
+	}
+	s.sessions = append(s.sessions, session)
+	return session
+}
+
+func (s *Server) removeSession(target *session) {
+	for i, session := range s.sessions {
+		if session == target {
+			s.sessions = append(s.sessions[:i], s.sessions[i+1:]...)
+			return
+		}
+	}
+}
+
+func randomToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -3,10 +3,118 @@ package dtfapi
 import (
 	"errors"
 	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"resty.dev/v3"
 )
 
 var ErrMissingToken = errors.New("access token is not provided")
 var ErrInvalidCredentials = errors.New("invalid email & password")
+var ErrRefreshTokenInvalid = errors.New("refresh token is invalid or expired")
+
+// Sentinels for every ErrorKind, use them with errors.Is
+var (
+	ErrUnauthorized      = errors.New("unauthorized")
+	ErrTokenExpired      = errors.New("access token expired")
+	ErrRateLimited       = errors.New("rate limited")
+	ErrNotFound          = errors.New("not found")
+	ErrForbidden         = errors.New("forbidden")
+	ErrValidation        = errors.New("validation failed")
+	ErrServer            = errors.New("server error")
+	ErrTransport         = errors.New("transport error")
+	ErrMalformedResponse = errors.New("malformed response")
+	ErrUnknown           = errors.New("unknown api error")
+)
+
+type ErrorKind int
+
+const (
+	KindUnknown ErrorKind = iota
+	KindUnauthorized
+	KindTokenExpired
+	KindRateLimited
+	KindNotFound
+	KindForbidden // includes banned accounts
+	KindValidation
+	KindServer
+	KindTransport         // request didn't get a response
+	KindMalformedResponse // response can't be decoded
+)
+
+var kindSentinels = map[ErrorKind]error{
+	KindUnknown:           ErrUnknown,
+	KindUnauthorized:      ErrUnauthorized,
+	KindTokenExpired:      ErrTokenExpired,
+	KindRateLimited:       ErrRateLimited,
+	KindNotFound:          ErrNotFound,
+	KindForbidden:         ErrForbidden,
+	KindValidation:        ErrValidation,
+	KindServer:            ErrServer,
+	KindTransport:         ErrTransport,
+	KindMalformedResponse: ErrMalformedResponse,
+}
+
+func (k ErrorKind) String() string {
+	return kindSentinels[k].Error()
+}
+
+// Error is returned by every DtfService method when api call fails.
+// It is built from http status and both v2 and v3 error envelopes.
+type Error struct {
+	Kind       ErrorKind
+	StatusCode int    // 0 for transport errors
+	Code       int    // api error code from the envelope
+	Message    string // api error message from the envelope
+	RetryAfter time.Duration
+
+	// Err is DtfErrorV2, DtfErrorV3 or transport error
+	Err error
+	// reason is a more specific sentinel, e.g. ErrInvalidCredentials
+	reason error
+}
+
+func (err *Error) Error() string {
+	var b strings.Builder
+	b.WriteString("dtf api: " + err.Kind.String())
+	if err.StatusCode != 0 {
+		_, _ = fmt.Fprintf(&b, " (status: %d)", err.StatusCode)
+	}
+	if err.Message != "" {
+		_, _ = fmt.Fprintf(&b, ": %s (code: %d)", err.Message, err.Code)
+	} else if err.Err != nil {
+		b.WriteString(": " + err.Err.Error())
+	}
+	return b.String()
+}
+
+func (err *Error) Unwrap() []error {
+	errs := []error{kindSentinels[err.Kind]}
+	if err.reason != nil {
+		errs = append(errs, err.reason)
+	}
+	if err.Err != nil {
+		errs = append(errs, err.Err)
+	}
+	return errs
+}
+
+// Temporary reports whether request may succeed later
+func (err *Error) Temporary() bool {
+	switch err.Kind {
+	case KindRateLimited, KindServer, KindTransport:
+		return true
+	}
+	return false
+}
+
+// apiEnvelope is a common part of v2 and v3 errors
+type apiEnvelope interface {
+	error
+	code() int
+	message() string
+}
 
 type DtfErrorV2 struct {
 	Message string `json:"message"`
@@ -19,6 +127,9 @@ func (err DtfErrorV2) Error() string {
 	return fmt.Sprintf(`api error: %s (code: %d)`, err.Message, err.Err.Code)
 }
 
+func (err DtfErrorV2) code() int       { return err.Err.Code }
+func (err DtfErrorV2) message() string { return err.Message }
+
 type DtfErrorV3 struct {
 	Message string `json:"message"`
 	Code    int    `json:"code"`
@@ -27,3 +138,80 @@ type DtfErrorV3 struct {
 func (err DtfErrorV3) Error() string {
 	return fmt.Sprintf(`api error: %s (code: %d)`, err.Message, err.Code)
 }
+
+func (err DtfErrorV3) code() int       { return err.Code }
+func (err DtfErrorV3) message() string { return err.Message }
+
+// requestError classifies failed request and marks it if it was retried.
+// err is request execution error, envelope is decoded api error body.
+func requestError(resp *resty.Response, err error, envelope apiEnvelope) error {
+	return wrapRetried(resp, classifyError(resp, err, envelope))
+}
+
+func classifyError(resp *resty.Response, err error, envelope apiEnvelope) *Error {
+	result := &Error{Err: err}
+
+	status := 0
+	if resp != nil && resp.RawResponse != nil {
+		status = resp.StatusCode()
+	}
+	result.StatusCode = status
+
+	if envelope != nil && (envelope.message() != "" || envelope.code() != 0) {
+		result.Code = envelope.code()
+		result.Message = envelope.message()
+		if result.Err == nil {
+			result.Err = envelope
+		}
+	}
+
+	switch {
+	case status == 0:
+		result.Kind = KindTransport
+	case status < 400 && err != nil:
+		result.Kind = KindMalformedResponse
+	case status == http.StatusUnauthorized:
+		result.Kind = KindUnauthorized
+		if isExpiredMessage(result.Message) {
+			result.Kind = KindTokenExpired
+		}
+	case status == http.StatusForbidden:
+		result.Kind = KindForbidden
+	case status == http.StatusNotFound:
+		result.Kind = KindNotFound
+	case status == http.StatusTooManyRequests:
+		result.Kind = KindRateLimited
+		result.RetryAfter, _ = parseRetryAfter(resp.Header().Get("Retry-After"))
+	case status == http.StatusBadRequest || status == http.StatusUnprocessableEntity:
+		result.Kind = KindValidation
+	case status >= 500:
+		result.Kind = KindServer
+	default:
+		result.Kind = KindUnknown
+	}
+
+	return result
+}
+
+func isExpiredMessage(message string) bool {
+	message = strings.ToLower(message)
+	return strings.Contains(message, "expired") || strings.Contains(message, "истек")
+}
+
+var ErrRetriesExhausted = errors.New("retries exhausted")
+
+// RetryError is returned when request still fails after all retry attempts.
+// Use errors.Is(err, ErrRetriesExhausted) to check it,
+// original error is available via errors.As/Unwrap.
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+func (err *RetryError) Error() string {
+	return fmt.Sprintf("%s after %d attempts: %s", ErrRetriesExhausted, err.Attempts, err.Err)
+}
+
+func (err *RetryError) Unwrap() []error {
+	return []error{ErrRetriesExhausted, err.Err}
+}
@@ -0,0 +1,116 @@
+package dtfapi
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+type options struct {
+	baseUrl     string
+	rateLimit   rate.Limit
+	burst       int
+	timeout     time.Duration
+	transport   http.RoundTripper
+	logger      *slog.Logger
+	userAgents  []string
+	retryPolicy RetryPolicy
+}
+
+// Option configures NewClient and NewService.
+// Options which make no sense for the constructor are ignored.
+type Option func(*options)
+
+func defaultOptions() options {
+	return options{
+		rateLimit:   rate.Limit(1 / 3.0),
+		burst:       3,
+		logger:      slog.Default(),
+		userAgents:  userAgents,
+		retryPolicy: DefaultRetryPolicy(),
+	}
+}
+
+func applyOptions(opts []Option) options {
+	result := defaultOptions()
+	for _, opt := range opts {
+		opt(&result)
+	}
+	return result
+}
+
+// WithBaseURL points client to another Osnova based site or local server.
+// Default is DtfApi.
+func WithBaseURL(baseUrl string) Option {
+	return func(o *options) {
+		o.baseUrl = baseUrl
+	}
+}
+
+// WithRateLimit sets requests per second limit and burst size.
+// Default is 1 request per 3 seconds with burst of 3.
+func WithRateLimit(limit rate.Limit, burst int) Option {
+	return func(o *options) {
+		o.rateLimit = limit
+		o.burst = burst
+	}
+}
+
+// WithTimeout sets timeout for every request attempt. Default is no timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithHTTPTransport replaces http transport, useful for tests and proxies.
+func WithHTTPTransport(transport http.RoundTripper) Option {
+	return func(o *options) {
+		o.transport = transport
+	}
+}
+
+// WithLogger sets logger for the package. Default is slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		if logger != nil {
+			o.logger = logger
+		}
+	}
+}
+
+// WithUserAgents sets list of user agents, random one is used for each request.
+func WithUserAgents(agents []string) Option {
+	return func(o *options) {
+		if len(agents) > 0 {
+			o.userAgents = agents
+		}
+	}
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retryPolicy = policy
+	}
+}
+
+// restyLogger makes resty write its logs into slog
+type restyLogger struct {
+	logger *slog.Logger
+}
+
+func (l restyLogger) Errorf(format string, v ...any) {
+	l.logger.Error(fmt.Sprintf(format, v...))
+}
+
+func (l restyLogger) Warnf(format string, v ...any) {
+	l.logger.Warn(fmt.Sprintf(format, v...))
+}
+
+func (l restyLogger) Debugf(format string, v ...any) {
+	l.logger.Debug(fmt.Sprintf(format, v...))
+}
@@ -0,0 +1,146 @@
+package dtfapi_test
+
+import (
+	"bytes"
+	"context"
+	"dtf/game_draw/pkg/dtfapi"
+	"dtf/game_draw/pkg/dtfapi/dtfapitest"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fixtures are responses of real api with tokens and emails redacted,
+// run with -record to refresh them and with -update to rewrite golden files
+var (
+	record = flag.Bool("record", false, "record fixtures from real dtf api")
+	update = flag.Bool("update", false, "rewrite golden files")
+)
+
+const (
+	fixturesDir = "testdata/fixtures"
+	goldenDir   = "testdata/golden"
+)
+
+// searchDateFrom is older than every recorded search result except the last one
+var searchDateFrom = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+func newReplayService(t *testing.T) *dtfapi.DtfService {
+	t.Helper()
+
+	mode := dtfapitest.ModeReplay
+	if *record {
+		mode = dtfapitest.ModeRecord
+	}
+	recorder := dtfapitest.NewRecorder(fixturesDir, mode)
+
+	client := dtfapi.NewClient(context.Background(), recorder.ClientOptions()...)
+	t.Cleanup(func() { _ = client.Close() })
+
+	return dtfapi.NewService(client.Client())
+}
+
+// goldenPost is BlogPost with blocks which can be compared as json
+type goldenPost struct {
+	dtfapi.BlogPost
+	Blocks []goldenBlock
+}
+
+type goldenBlock struct {
+	Type string
+	Data any
+}
+
+func toGolden(post dtfapi.BlogPost) goldenPost {
+	post.PublishedAt = post.PublishedAt.UTC()
+	post.Author.CreatedAt = post.Author.CreatedAt.UTC()
+
+	blocks := make([]goldenBlock, 0, len(post.Blocks))
+	for _, block := range post.Blocks {
+		data := any(block)
+		// list items are unexported
+		if list, ok := block.(dtfapi.DataList); ok {
+			data = struct {
+				Items   []string
+				Ordered bool
+			}{list.Items(), list.IsOrdered()}
+		}
+		blocks = append(blocks, goldenBlock{Type: block.Type(), Data: data})
+	}
+
+	return goldenPost{BlogPost: post, Blocks: blocks}
+}
+
+func assertGolden(t *testing.T, name string, value any) {
+	t.Helper()
+
+	var got bytes.Buffer
+	encoder := json.NewEncoder(&got)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		t.Fatalf("encode %s: %v", name, err)
+	}
+
+	path := filepath.Join(goldenDir, name+".json")
+	if *update {
+		if err := os.MkdirAll(goldenDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file: %v", err)
+	}
+	if !bytes.Equal(got.Bytes(), want) {
+		t.Errorf("%s differs from golden file %s:\n%s", name, path, got.String())
+	}
+}
+
+func TestReplayGetPostById(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+	}{
+		// every block type the parser knows, cover, unknown quiz block
+		{name: "post_raffle", id: "2871432"},
+		// repost without blocks in the user's blog
+		{name: "post_repost", id: "2871555"},
+	}
+
+	service := newReplayService(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post, err := service.GetPostById(context.Background(), tt.id)
+			if err != nil {
+				t.Fatalf("GetPostById() error = %v", err)
+			}
+			assertGolden(t, tt.name, toGolden(post))
+		})
+	}
+}
+
+func TestReplaySearchNews(t *testing.T) {
+	service := newReplayService(t)
+
+	// the second page has a post with broken block, which is skipped,
+	// and a post older than dateFrom, which ends the search
+	posts, err := service.SearchNews(context.Background(), "Розыгрыш", searchDateFrom)
+	if err != nil {
+		t.Fatalf("SearchNews() error = %v", err)
+	}
+
+	golden := make([]goldenPost, 0, len(posts))
+	for _, post := range posts {
+		golden = append(golden, toGolden(post))
+	}
+	assertGolden(t, "search_rozygrysh", golden)
+}
@@ -0,0 +1,169 @@
+package dtfapi
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"resty.dev/v3"
+)
+
+// RetryPolicy describes how failed requests are retried.
+// GET requests are retried on timeouts, 429 and 5xx responses,
+// POST requests are retried only if connection wasn't established,
+// because api might have already processed them (double comment, etc).
+type RetryPolicy struct {
+	MaxRetries int           // 0 disables retries
+	MinWait    time.Duration // first backoff, next ones grow exponentially with jitter
+	MaxWait    time.Duration // backoff cap
+
+	// Retry-After header is honored, but if api asks to wait longer,
+	// request fails immediately without waiting.
+	MaxRetryAfter time.Duration
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:    3,
+		MinWait:       time.Second,
+		MaxWait:       15 * time.Second,
+		MaxRetryAfter: time.Minute,
+	}
+}
+
+func (p RetryPolicy) shouldRetry(res *resty.Response, err error) bool {
+	// request wasn't sent at all (limiter, middlewares)
+	if res == nil || res.Request == nil {
+		return false
+	}
+	if res.Request.Context().Err() != nil {
+		return false
+	}
+
+	if err != nil && isConnectionError(err) {
+		return true
+	}
+
+	if !isIdempotent(res.Request.Method) {
+		return false
+	}
+
+	if err != nil {
+		return isTemporaryError(err)
+	}
+
+	switch status := res.StatusCode(); {
+	case status == http.StatusTooManyRequests:
+		retryAfter, ok := parseRetryAfter(res.Header().Get("Retry-After"))
+		return !ok || retryAfter <= p.MaxRetryAfter
+	case status >= 500 && status != http.StatusNotImplemented:
+		return true
+	}
+
+	return false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// isConnectionError reports whether request never reached the server
+func isConnectionError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// isTemporaryError reports whether request might succeed if it's repeated
+func isTemporaryError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var certErr *tls.CertificateVerificationError
+	if errors.As(err, &certErr) {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		isConnectionError(err)
+}
+
+// parseRetryAfter supports both delta-seconds and http-date formats,
+// date in the past means retry right away
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	return max(time.Until(date), 0), true
+}
+
+func (c *apiClient) logRetry(res *resty.Response, err error) {
+	if res == nil || res.Request == nil {
+		return
+	}
+	// resty resets err before calling hooks, parsing errors are kept in response
+	if err == nil {
+		err = res.Err
+	}
+
+	c.logger.Warn(
+		"retrying dtf request",
+		"method", res.Request.Method,
+		"url", res.Request.URL,
+		"attempt", res.Request.Attempt,
+		"status", res.StatusCode(),
+		"err", err,
+	)
+}
+
+// wrapRetried marks errors of requests which were sent more than once,
+// so callers can tell a retried out failure from the first one.
+func wrapRetried(resp *resty.Response, err error) error {
+	if err == nil || resp == nil || resp.Request == nil || resp.Request.Attempt <= 1 {
+		return err
+	}
+
+	return &RetryError{
+		Attempts: resp.Request.Attempt,
+		Err:      err,
+	}
+}
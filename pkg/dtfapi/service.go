@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 	"log/slog"
 	"strconv"
 	"time"
@@ -15,15 +16,29 @@ import (
 
 const DtfApi string = "https://api.dtf.ru/"
 
+// maxSearchPages protects from endless pagination
+// if api keeps returning the same cursor
+const maxSearchPages = 50
+
 type DtfService struct {
 	client *resty.Client
+	logger *slog.Logger
 }
 
-func NewService(client *resty.Client) *DtfService {
-	client.SetBaseURL(DtfApi)
+// NewService accepts WithBaseURL and WithLogger options.
+// If base url is set neither in options nor in the client, DtfApi is used.
+func NewService(client *resty.Client, opts ...Option) *DtfService {
+	options := applyOptions(opts)
+	switch {
+	case options.baseUrl != "":
+		client.SetBaseURL(options.baseUrl)
+	case client.BaseURL() == "":
+		client.SetBaseURL(DtfApi)
+	}
 
 	return &DtfService{
 		client: client,
+		logger: options.logger,
 	}
 }
 
@@ -53,21 +68,24 @@ func (c *DtfService) EmailLogin(ctx context.Context, email, password string) (To
 		Post("/v3.0/auth/email/login")
 
 	if err != nil {
-		return Tokens{}, err
+		return Tokens{}, requestError(resp, err, apiError)
 	}
 
 	if resp.IsError() {
+		dtfErr := classifyError(resp, nil, apiError)
+		// 104 is used for other auth errors too
 		if apiError.Code == 104 && apiError.Message == "Invalid login or password" {
-			return Tokens{}, ErrInvalidCredentials
+			dtfErr.Kind = KindUnauthorized
+			dtfErr.reason = ErrInvalidCredentials
 		}
-
-		return Tokens{}, apiError
+		return Tokens{}, wrapRetried(resp, dtfErr)
 	}
 
 	return Tokens{
-		AccessToken:      apiResult.Data.AccessToken,
-		RefreshToken:     apiResult.Data.RefreshToken,
-		AccessExpiration: time.Unix(apiResult.Data.AccessExpTimestamp, 0),
+		AccessToken:       apiResult.Data.AccessToken,
+		RefreshToken:      apiResult.Data.RefreshToken,
+		AccessExpiration:  time.Unix(apiResult.Data.AccessExpTimestamp, 0),
+		RefreshExpiration: time.Unix(apiResult.Data.RefreshExpTimestamp, 0),
 	}, nil
 }
 
@@ -103,24 +121,38 @@ func (c *DtfService) RefreshToken(ctx context.Context, refreshToken string) (Tok
 		Post("/v3.0/auth/refresh")
 
 	if err != nil {
-		return Tokens{}, err
+		return Tokens{}, requestError(resp, err, apiError)
 	}
 
 	if resp.IsError() {
-		return Tokens{}, apiError
+		dtfErr := classifyError(resp, nil, apiError)
+		// refresh token is dead, user has to log in again
+		if dtfErr.Kind == KindUnauthorized || dtfErr.Kind == KindTokenExpired || dtfErr.Kind == KindValidation {
+			dtfErr.reason = ErrRefreshTokenInvalid
+		}
+		return Tokens{}, wrapRetried(resp, dtfErr)
 	}
 
 	if apiResult.Message == "Refresh token is missing" {
-		return Tokens{}, DtfErrorV3{
+		envelope := DtfErrorV3{
 			Message: "Refresh token is missing",
 			Code:    400, // this is a lie, fucking api sends 200. but i dont care.
 		}
+		return Tokens{}, &Error{
+			Kind:       KindValidation,
+			StatusCode: resp.StatusCode(),
+			Code:       envelope.Code,
+			Message:    envelope.Message,
+			Err:        envelope,
+			reason:     ErrRefreshTokenInvalid,
+		}
 	}
 
 	tokens := Tokens{
-		AccessToken:      apiResult.Data.AccessToken,
-		RefreshToken:     apiResult.Data.RefreshToken,
-		AccessExpiration: time.Unix(apiResult.Data.AccessExpTimestamp, 0),
+		AccessToken:       apiResult.Data.AccessToken,
+		RefreshToken:      apiResult.Data.RefreshToken,
+		AccessExpiration:  time.Unix(apiResult.Data.AccessExpTimestamp, 0),
+		RefreshExpiration: time.Unix(apiResult.Data.RefreshExpTimestamp, 0),
 	}
 
 	return tokens, nil
@@ -136,24 +168,27 @@ type SelfUserResponse struct {
 }
 
 // SelfUserInfo returns information about logged in user
-func (c *DtfService) SelfUserInfo(ctx context.Context, accessToken string) (UserInfo, error) {
+func (c *DtfService) SelfUserInfo(ctx context.Context, ts TokenSource) (UserInfo, error) {
 	var apiResponse SelfUserResponse
-	var apiError DtfErrorV2
 
-	req := c.withAuth(accessToken)
-	resp, err := req.
-		SetContext(ctx).
-		SetResult(&apiResponse).
-		SetError(&apiError).
-		Get("/v2.1/subsite/me")
+	err := c.authorized(ctx, ts, func(accessToken string) error {
+		var apiError DtfErrorV2
+
+		req := c.withAuth(accessToken)
+		resp, err := req.
+			SetContext(ctx).
+			SetResult(&apiResponse).
+			SetError(&apiError).
+			Get("/v2.1/subsite/me")
+		if err != nil || resp.IsError() {
+			return requestError(resp, err, apiError)
+		}
+		return nil
+	})
 	if err != nil {
 		return UserInfo{}, err
 	}
 
-	if resp.IsError() {
-		return UserInfo{}, apiError
-	}
-
 	return UserInfo{
 		Id:   apiResponse.Result.Id,
 		Url:  apiResponse.Result.Url,
@@ -168,13 +203,30 @@ type PostBlock struct {
 	Data   json.RawMessage `json:"data"`
 }
 
+type SubsiteResponse struct {
+	Id      int    `json:"id"`
+	Name    string `json:"name"`
+	Url     string `json:"url"`
+	Created int64  `json:"created"` // registration unix time, 0 if not sent
+}
+
 type PostResponse struct {
-	Id       int         `json:"id"`
-	Date     int         `json:"date"`
-	Title    string      `json:"title"`
-	Uri      string      `json:"url"`
-	Blocks   []PostBlock `json:"blocks"`
-	RepostId *int        `json:"repostId"`
+	Id       int             `json:"id"`
+	Date     int             `json:"date"`
+	Title    string          `json:"title"`
+	Uri      string          `json:"url"`
+	Blocks   []PostBlock     `json:"blocks"`
+	RepostId *int            `json:"repostId"`
+	Author   SubsiteResponse `json:"author"`
+	Subsite  SubsiteResponse `json:"subsite"`
+	Likes    struct {
+		Counter int `json:"counter"`
+	} `json:"likes"`
+	Counters struct {
+		Comments int `json:"comments"`
+		Views    int `json:"views"`
+		Reposts  int `json:"reposts"`
+	} `json:"counters"`
 }
 
 func (c *DtfService) GetPostById(
@@ -196,10 +248,8 @@ func (c *DtfService) GetPostById(
 		SetResult(&apiResponse).
 		SetError(&apiError).
 		Get("/v2.10/content")
-	if err != nil {
-		return BlogPost{}, err
-	} else if resp.IsError() {
-		return BlogPost{}, apiError
+	if err != nil || resp.IsError() {
+		return BlogPost{}, requestError(resp, err, apiError)
 	}
 
 	blogPost, err := mapPostResponseToBlogPost(&apiResponse.Post)
@@ -215,123 +265,202 @@ type SearchPostResponse struct {
 		Posts []struct {
 			Data PostResponse `json:"data"`
 		} `json:"items"`
+		LastId           int   `json:"lastId"`
+		LastSortingValue int64 `json:"lastSortingValue"`
 	} `json:"result"`
 }
 
-// SearchNews searches DTF posts by query string starting from dateFrom.
-// Returns posts sorted by date.
+// SearchNewsPage returns a single page of search results.
+// Pass zero SearchCursor to get the first page,
+// then use SearchPage.Next to get the following ones.
 // Mapping errors for individual posts are logged and skipped.
-func (c *DtfService) SearchNews(
+func (c *DtfService) SearchNewsPage(
 	ctx context.Context,
 	query string,
 	dateFrom time.Time,
-) ([]BlogPost, error) {
+	cursor SearchCursor,
+) (SearchPage, error) {
 	var apiResponse SearchPostResponse
 	var apiError DtfErrorV2
 
+	params := map[string]string{
+		"markdown":  "false",
+		"sorting":   "date",
+		"q":         query,
+		"title":     "true",
+		"editorial": "false",
+		"strict":    "false",
+		"dateFrom":  fmt.Sprintf("%d", dateFrom.Unix()),
+	}
+	if !cursor.IsZero() {
+		params["lastId"] = strconv.Itoa(cursor.LastId)
+		params["lastSortingValue"] = strconv.FormatInt(cursor.LastSortingValue, 10)
+	}
+
 	resp, err := c.client.
 		R().
 		SetResponseBodyUnlimitedReads(true).
 		SetContext(ctx).
-		SetQueryParams(map[string]string{
-			"markdown":  "false",
-			"sorting":   "date",
-			"q":         query,
-			"title":     "true",
-			"editorial": "false",
-			"strict":    "false",
-			"dateFrom":  fmt.Sprintf("%d", dateFrom.Unix()),
-		}).
+		SetQueryParams(params).
 		SetResult(&apiResponse).
 		SetError(&apiError).
 		Get("/v2.8/search/posts")
 	if err != nil {
-		return nil, err
+		return SearchPage{}, requestError(resp, err, apiError)
 	}
 	if resp.IsError() {
-		slog.Error("search news error", "body", resp.String())
-		return nil, apiError
+		c.logger.Error("search news error", "body", resp.String())
+		return SearchPage{}, requestError(resp, err, apiError)
 	}
 
-	result := make([]BlogPost, 0, len(apiResponse.Result.Posts))
+	page := SearchPage{
+		Posts: make([]BlogPost, 0, len(apiResponse.Result.Posts)),
+	}
 	for _, apiPost := range apiResponse.Result.Posts {
+		// api may return posts older than dateFrom on the last pages,
+		// everything after them is older too
+		if int64(apiPost.Data.Date) < dateFrom.Unix() {
+			page.Exhausted = true
+			break
+		}
+
 		blogPost, err := mapPostResponseToBlogPost(&apiPost.Data)
 		if err != nil {
 			// мы должны отправить хоть что-то любой ценой
 			// logging only and continue
-			slog.Error("blogpost unmarshal error", "err", err)
+			c.logger.Error("blogpost unmarshal error", "err", err)
 			continue
 		}
-		result = append(result, blogPost)
+		page.Posts = append(page.Posts, blogPost)
 	}
 
-	return result, nil
-}
+	next := SearchCursor{
+		LastId:           apiResponse.Result.LastId,
+		LastSortingValue: apiResponse.Result.LastSortingValue,
+	}
+	if len(apiResponse.Result.Posts) == 0 || next.IsZero() || next == cursor {
+		page.Exhausted = true
+	}
+	if !page.Exhausted {
+		page.Next = next
+	}
 
-type ReactToPostRequest struct {
-	Type int `json:"type"`
+	return page, nil
 }
 
-// Reacts to post with <Heart> reaction
-func (c *DtfService) ReactToPost(
+// SearchNewsIter iterates over every post found by query since dateFrom,
+// requesting next pages lazily.
+// Iteration stops after the first error.
+func (c *DtfService) SearchNewsIter(
 	ctx context.Context,
-	accessToken string,
-	postId int,
-) error {
-	var apiError DtfErrorV2
+	query string,
+	dateFrom time.Time,
+) iter.Seq2[BlogPost, error] {
+	return func(yield func(BlogPost, error) bool) {
+		var cursor SearchCursor
+		for range maxSearchPages {
+			page, err := c.SearchNewsPage(ctx, query, dateFrom, cursor)
+			if err != nil {
+				yield(BlogPost{}, err)
+				return
+			}
 
-	req := c.withAuth(accessToken)
+			for _, post := range page.Posts {
+				if !yield(post, nil) {
+					return
+				}
+			}
 
-	resp, err := req.
-		SetContext(ctx).
-		SetError(&apiError).
-		SetMultipartFormData(map[string]string{
-			"type": strconv.Itoa(1), // This is the HEART reaction Id (id == 1)
-		}).
-		SetPathParam("post_id", strconv.Itoa(postId)).
-		Post("/v2.5/content/{post_id}/react")
+			if page.Exhausted {
+				return
+			}
+			cursor = page.Next
+		}
 
-	if err != nil {
-		return err
+		c.logger.Warn("search pages limit reached", "query", query, "limit", maxSearchPages)
 	}
+}
 
-	if resp.IsError() {
-		return apiError
+// SearchNews searches DTF posts by query string starting from dateFrom.
+// Walks through all result pages, so every matching post is returned.
+// Returns posts sorted by date.
+// Mapping errors for individual posts are logged and skipped.
+func (c *DtfService) SearchNews(
+	ctx context.Context,
+	query string,
+	dateFrom time.Time,
+) ([]BlogPost, error) {
+	var result []BlogPost
+	for post, err := range c.SearchNewsIter(ctx, query, dateFrom) {
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, post)
 	}
 
-	return nil
+	return result, nil
 }
 
-func (c *DtfService) PostComment(ctx context.Context, accessToken string, postId int, text string) error {
-	var apiError DtfErrorV2
-	req := c.withAuth(accessToken)
-	resp, err := req.
-		SetContext(ctx).
-		SetMultipartFormData(map[string]string{
-			"id":   strconv.Itoa(postId),
-			"text": text,
-
-			// User's id.
-			// This can be used if we need to comment someone.
-			"reply_to": "0", // we don't need to reply anyone, just post under the blogpost
+type ReactToPostRequest struct {
+	Type int `json:"type"`
+}
 
-			// we won't use it in real world,
-			// but it needs to be described for proper API call.
-			// IINM this is a list of url links or ids
-			"attachments": "[]", // providing empty attachment list, no images
-		}).
-		SetError(&apiError).
-		Post("/v2.4/comment/add")
+// Reacts to post with <Heart> reaction
+func (c *DtfService) ReactToPost(
+	ctx context.Context,
+	ts TokenSource,
+	postId int,
+) error {
+	return c.authorized(ctx, ts, func(accessToken string) error {
+		var apiError DtfErrorV2
+
+		req := c.withAuth(accessToken)
+
+		resp, err := req.
+			SetContext(ctx).
+			SetError(&apiError).
+			SetMultipartFormData(map[string]string{
+				"type": strconv.Itoa(1), // This is the HEART reaction Id (id == 1)
+			}).
+			SetPathParam("post_id", strconv.Itoa(postId)).
+			Post("/v2.5/content/{post_id}/react")
+
+		if err != nil || resp.IsError() {
+			return requestError(resp, err, apiError)
+		}
 
-	if err != nil {
-		return err
-	}
+		return nil
+	})
+}
 
-	if resp.IsError() {
-		return apiError
-	}
+func (c *DtfService) PostComment(ctx context.Context, ts TokenSource, postId int, text string) error {
+	return c.authorized(ctx, ts, func(accessToken string) error {
+		var apiError DtfErrorV2
+		req := c.withAuth(accessToken)
+		resp, err := req.
+			SetContext(ctx).
+			SetMultipartFormData(map[string]string{
+				"id":   strconv.Itoa(postId),
+				"text": text,
+
+				// User's id.
+				// This can be used if we need to comment someone.
+				"reply_to": "0", // we don't need to reply anyone, just post under the blogpost
+
+				// we won't use it in real world,
+				// but it needs to be described for proper API call.
+				// IINM this is a list of url links or ids
+				"attachments": "[]", // providing empty attachment list, no images
+			}).
+			SetError(&apiError).
+			Post("/v2.4/comment/add")
+
+		if err != nil || resp.IsError() {
+			return requestError(resp, err, apiError)
+		}
 
-	return nil
+		return nil
+	})
 }
 
 func (c *DtfService) withAuth(accessToken string) *resty.Request {
@@ -340,56 +469,62 @@ func (c *DtfService) withAuth(accessToken string) *resty.Request {
 }
 
 func mapPostResponseToBlogPost(response *PostResponse) (BlogPost, error) {
-	var blocks []DataBlock
-
+	var coverUrl string
+	blocks := make([]DataBlock, 0, len(response.Blocks))
 	for _, block := range response.Blocks {
-		switch block.Type {
-		case "text":
-			var textBlock struct {
-				Text string `json:"text"`
-			}
-			err := json.Unmarshal(block.Data, &textBlock)
-			if err != nil {
-				return BlogPost{}, err
-			}
-			blocks = append(blocks, DataText{
-				HtmlText: textBlock.Text,
-			})
-		case "header":
-			var headerBlock struct {
-				Style string `json:"style"`
-				Text  string `json:"text"`
-			}
-			err := json.Unmarshal(block.Data, &headerBlock)
-			if err != nil {
-				return BlogPost{}, err
-			}
-			blocks = append(blocks, DataHeader{
-				Style: headerBlock.Style,
-				Text:  headerBlock.Text,
-			})
-		case "list":
-			var listBlock struct {
-				Items []string `json:"items"`
-				Type  string   `json:"type"`
-			}
-			err := json.Unmarshal(block.Data, &listBlock)
-			if err != nil {
-				return BlogPost{}, err
-			}
-			blocks = append(blocks, DataList{
-				items: listBlock.Items,
-			})
+		dataBlock, err := mapPostBlock(block)
+		if err != nil {
+			return BlogPost{}, fmt.Errorf("block %q of post #%d: %w", block.Type, response.Id, err)
+		}
+		blocks = append(blocks, dataBlock)
+
+		if coverUrl == "" && block.Cover {
+			coverUrl = blockImageUrl(dataBlock)
 		}
-		// TODO: Add other types when need comes
-		// Also might be better idea to export this code into another function
 	}
 
 	return BlogPost{
-		Id:        response.Id,
-		Title:     response.Title,
-		Uri:       response.Uri,
-		Blocks:    blocks,
-		RepliedTo: response.RepostId,
+		Id:          response.Id,
+		Title:       response.Title,
+		Uri:         response.Uri,
+		Blocks:      blocks,
+		RepliedTo:   response.RepostId,
+		PublishedAt: time.Unix(int64(response.Date), 0),
+		Author: UserInfo{
+			Id:        response.Author.Id,
+			Url:       response.Author.Url,
+			Name:      response.Author.Name,
+			CreatedAt: unixOrZero(response.Author.Created),
+		},
+		Subsite: Subsite{
+			Id:   response.Subsite.Id,
+			Url:  response.Subsite.Url,
+			Name: response.Subsite.Name,
+		},
+		Likes:    response.Likes.Counter,
+		Comments: response.Counters.Comments,
+		Views:    response.Counters.Views,
+		Reposts:  response.Counters.Reposts,
+		CoverUrl: coverUrl,
 	}, nil
 }
+
+func unixOrZero(ts int64) time.Time {
+	if ts <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(ts, 0)
+}
+
+// blockImageUrl returns image of the block if it has one
+func blockImageUrl(block DataBlock) string {
+	switch b := block.(type) {
+	case DataImage:
+		return b.Url
+	case DataGallery:
+		if len(b.Images) > 0 {
+			return b.Images[0].Url
+		}
+	}
+	return ""
+}
@@ -0,0 +1,245 @@
+package dtfapi_test
+
+import (
+	"context"
+	"dtf/game_draw/pkg/dtfapi"
+	"dtf/game_draw/pkg/dtfapi/dtfapitest"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	searchRoute  = "GET /v2.8/search/posts"
+	refreshRoute = "POST /v3.0/auth/refresh"
+)
+
+var testUser = dtfapitest.User{Id: 1, Name: "User", Email: "user@example.com", Password: "secret"}
+
+func newTestService(t *testing.T, opts ...dtfapitest.Option) (*dtfapitest.Server, *dtfapi.DtfService) {
+	t.Helper()
+
+	server := dtfapitest.NewServer(opts...)
+	t.Cleanup(server.Close)
+	server.AddUser(testUser)
+
+	client := dtfapi.NewClient(context.Background(), server.ClientOptions()...)
+	t.Cleanup(func() { _ = client.Close() })
+
+	return server, dtfapi.NewService(client.Client())
+}
+
+func login(t *testing.T, service *dtfapi.DtfService) dtfapi.Tokens {
+	t.Helper()
+
+	tokens, err := service.EmailLogin(context.Background(), testUser.Email, testUser.Password)
+	if err != nil {
+		t.Fatalf("EmailLogin() error = %v", err)
+	}
+	return tokens
+}
+
+func TestEmailLogin(t *testing.T) {
+	_, service := newTestService(t)
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  error
+	}{
+		{name: "valid credentials", password: testUser.Password},
+		{name: "wrong password", password: "wrong", wantErr: dtfapi.ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := service.EmailLogin(context.Background(), testUser.Email, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("EmailLogin() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (tokens.AccessToken == "" || tokens.RefreshToken == "") {
+				t.Errorf("EmailLogin() = %+v, want both tokens", tokens)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenRotates(t *testing.T) {
+	ctx := context.Background()
+	_, service := newTestService(t)
+	tokens := login(t, service)
+
+	rotated, err := service.RefreshToken(ctx, tokens.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshToken() error = %v", err)
+	}
+	if rotated.RefreshToken == tokens.RefreshToken {
+		t.Error("RefreshToken() returned the same refresh token")
+	}
+
+	// previous pair is disposed by api
+	if _, err := service.RefreshToken(ctx, tokens.RefreshToken); !errors.Is(err, dtfapi.ErrRefreshTokenInvalid) {
+		t.Errorf("RefreshToken() with used token error = %v, want %v", err, dtfapi.ErrRefreshTokenInvalid)
+	}
+}
+
+func TestRefreshingTokenSource(t *testing.T) {
+	ctx := context.Background()
+	server, service := newTestService(t)
+	tokens := login(t, service)
+
+	var saved []dtfapi.Tokens
+	ts := dtfapi.NewRefreshingTokenSource(service, tokens,
+		func(_ context.Context, _, current dtfapi.Tokens) (dtfapi.Tokens, error) {
+			saved = append(saved, current)
+			return current, nil
+		})
+
+	// client still thinks token is valid, api rejects it
+	server.ExpireAccessTokens()
+
+	info, err := service.SelfUserInfo(ctx, ts)
+	if err != nil {
+		t.Fatalf("SelfUserInfo() error = %v", err)
+	}
+	if info.Id != testUser.Id {
+		t.Errorf("SelfUserInfo().Id = %d, want %d", info.Id, testUser.Id)
+	}
+	if got := server.RequestCount(refreshRoute); got != 1 {
+		t.Errorf("refresh requests = %d, want 1", got)
+	}
+	if len(saved) != 1 || saved[0] != ts.Tokens() {
+		t.Errorf("saved tokens = %+v, want the current pair once", saved)
+	}
+
+	// session is gone, there is nothing to refresh
+	server.RevokeSessions()
+	if _, err := service.SelfUserInfo(ctx, ts); !errors.Is(err, dtfapi.ErrRefreshTokenInvalid) {
+		t.Errorf("SelfUserInfo() after revoke error = %v, want %v", err, dtfapi.ErrRefreshTokenInvalid)
+	}
+}
+
+func TestSearchNewsWalksPages(t *testing.T) {
+	dateFrom := time.Now().Add(-24 * time.Hour)
+	server, service := newTestService(t, dtfapitest.WithPageSize(2))
+
+	for i := range 5 {
+		server.AddPost(dtfapitest.Post{
+			Title:    "Розыгрыш #" + strconv.Itoa(i),
+			Date:     dateFrom.Add(time.Duration(i+1) * time.Hour),
+			AuthorId: testUser.Id,
+		})
+	}
+	server.AddPost(dtfapitest.Post{Title: "Обзор Hades", Date: dateFrom.Add(time.Hour), AuthorId: testUser.Id})
+	server.AddPost(dtfapitest.Post{Title: "Старый розыгрыш", Date: dateFrom.Add(-time.Hour), AuthorId: testUser.Id})
+
+	posts, err := service.SearchNews(context.Background(), "розыгрыш", dateFrom)
+	if err != nil {
+		t.Fatalf("SearchNews() error = %v", err)
+	}
+	if len(posts) != 5 {
+		t.Fatalf("SearchNews() returned %d posts, want 5", len(posts))
+	}
+	for i := 1; i < len(posts); i++ {
+		if posts[i].PublishedAt.After(posts[i-1].PublishedAt) {
+			t.Errorf("post %d is newer than the previous one", posts[i].Id)
+		}
+	}
+	if posts[0].Author.Id != testUser.Id {
+		t.Errorf("author id = %d, want %d", posts[0].Author.Id, testUser.Id)
+	}
+	// the last page still has a cursor, so the empty one is requested too
+	if got := server.RequestCount(searchRoute); got != 4 {
+		t.Errorf("search requests = %d, want 4", got)
+	}
+}
+
+func TestSearchNewsPageFaults(t *testing.T) {
+	tests := []struct {
+		name         string
+		fault        dtfapitest.Fault
+		wantErr      error
+		wantRequests int
+	}{
+		{
+			name:         "retries server errors",
+			fault:        dtfapitest.Fault{Route: searchRoute, Times: 2, Status: http.StatusServiceUnavailable},
+			wantRequests: 3,
+		},
+		{
+			name:         "gives up after max retries",
+			fault:        dtfapitest.Fault{Route: searchRoute, Status: http.StatusBadGateway},
+			wantErr:      dtfapi.ErrServer,
+			wantRequests: 4,
+		},
+		{
+			name:         "honors short retry after",
+			fault:        dtfapitest.Fault{Route: searchRoute, Times: 1, Status: http.StatusTooManyRequests},
+			wantRequests: 2,
+		},
+		{
+			name: "fails fast on long retry after",
+			fault: dtfapitest.Fault{
+				Route: searchRoute, Times: 1, Status: http.StatusTooManyRequests, RetryAfter: 120,
+			},
+			wantErr:      dtfapi.ErrRateLimited,
+			wantRequests: 1,
+		},
+		{
+			name:         "malformed response",
+			fault:        dtfapitest.Fault{Route: searchRoute, Malformed: true},
+			wantErr:      dtfapi.ErrMalformedResponse,
+			wantRequests: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, service := newTestService(t)
+			server.AddPost(dtfapitest.Post{Title: "Розыгрыш ключа", AuthorId: testUser.Id})
+			server.InjectFault(tt.fault)
+
+			page, err := service.SearchNewsPage(context.Background(), "розыгрыш", time.Now().Add(-time.Hour), dtfapi.SearchCursor{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SearchNewsPage() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && len(page.Posts) != 1 {
+				t.Errorf("SearchNewsPage() returned %d posts, want 1", len(page.Posts))
+			}
+			if got := server.RequestCount(searchRoute); got != tt.wantRequests {
+				t.Errorf("search requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestPostCommentIsNotRetried(t *testing.T) {
+	const commentRoute = "POST /v2.4/comment/add"
+
+	ctx := context.Background()
+	server, service := newTestService(t)
+	post := server.AddPost(dtfapitest.Post{Title: "Розыгрыш ключа", AuthorId: testUser.Id})
+	ts := dtfapi.StaticToken(login(t, service).AccessToken)
+
+	server.InjectFault(dtfapitest.Fault{Route: commentRoute, Times: 1, Status: http.StatusBadGateway})
+	if err := service.PostComment(ctx, ts, post.Id, "Участвую"); !errors.Is(err, dtfapi.ErrServer) {
+		t.Fatalf("PostComment() error = %v, want %v", err, dtfapi.ErrServer)
+	}
+	if got := server.RequestCount(commentRoute); got != 1 {
+		t.Errorf("comment requests = %d, want 1", got)
+	}
+
+	if err := service.PostComment(ctx, ts, post.Id, "Участвую"); err != nil {
+		t.Fatalf("PostComment() error = %v", err)
+	}
+	comments, err := service.GetComments(ctx, post.Id)
+	if err != nil {
+		t.Fatalf("GetComments() error = %v", err)
+	}
+	if len(comments) != 1 || !strings.Contains(comments[0].HtmlText, "Участвую") {
+		t.Errorf("GetComments() = %+v, want the posted comment", comments)
+	}
+}
@@ -0,0 +1,179 @@
+package dtfapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// accessLeeway refreshes access token a bit earlier than it expires,
+// so it doesn't die in the middle of the request
+const accessLeeway = 30 * time.Second
+
+// TokenSource provides tokens for authenticated requests.
+type TokenSource interface {
+	// Token returns tokens which are valid right now
+	Token(ctx context.Context) (Tokens, error)
+	// Refresh is called when api rejected tokens.
+	// Implementations must refresh only if rejected tokens are still the current ones.
+	Refresh(ctx context.Context, rejected Tokens) (Tokens, error)
+}
+
+// RefreshCallback is called after tokens were rotated.
+// Previous pair is already disposed by api, new one must be persisted.
+// Returned tokens are used from now on: current ones, or newer ones
+// from the storage if somebody replaced previous pair there.
+type RefreshCallback func(ctx context.Context, previous, current Tokens) (Tokens, error)
+
+type staticTokenSource struct {
+	tokens Tokens
+}
+
+// StaticToken returns TokenSource which never refreshes the access token
+func StaticToken(accessToken string) TokenSource {
+	return staticTokenSource{
+		tokens: Tokens{AccessToken: accessToken},
+	}
+}
+
+func (s staticTokenSource) Token(_ context.Context) (Tokens, error) {
+	if s.tokens.AccessToken == "" {
+		return Tokens{}, ErrMissingToken
+	}
+	return s.tokens, nil
+}
+
+func (s staticTokenSource) Refresh(_ context.Context, _ Tokens) (Tokens, error) {
+	return Tokens{}, ErrTokenExpired
+}
+
+// RefreshingTokenSource refreshes access token when it is expired or rejected by api.
+// Every refresh token is used exactly once: concurrent callers wait for
+// the running refresh and get its result.
+type RefreshingTokenSource struct {
+	service   *DtfService
+	onRefresh RefreshCallback
+
+	mu     sync.Mutex
+	tokens Tokens
+	// the last pair passed to onRefresh successfully, if tokens differ,
+	// saving failed and is repeated on the next call
+	saved Tokens
+	// dead refresh token and the error it failed with,
+	// api disposes it, so there is no point to try it again
+	deadRefresh string
+	deadErr     error
+}
+
+var _ TokenSource = (*RefreshingTokenSource)(nil)
+
+func NewRefreshingTokenSource(service *DtfService, tokens Tokens, onRefresh RefreshCallback) *RefreshingTokenSource {
+	return &RefreshingTokenSource{
+		service:   service,
+		tokens:    tokens,
+		saved:     tokens,
+		onRefresh: onRefresh,
+	}
+}
+
+// Tokens returns current tokens without refreshing them
+func (ts *RefreshingTokenSource) Tokens() Tokens {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	return ts.tokens
+}
+
+func (ts *RefreshingTokenSource) Token(ctx context.Context) (Tokens, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.tokens.AccessToken != "" && time.Until(ts.tokens.AccessExpiration) > accessLeeway {
+		return ts.persistLocked(ctx)
+	}
+
+	return ts.refreshLocked(ctx)
+}
+
+func (ts *RefreshingTokenSource) Refresh(ctx context.Context, rejected Tokens) (Tokens, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	// someone already refreshed them
+	if ts.tokens.AccessToken != rejected.AccessToken {
+		return ts.persistLocked(ctx)
+	}
+
+	return ts.refreshLocked(ctx)
+}
+
+func (ts *RefreshingTokenSource) refreshLocked(ctx context.Context) (Tokens, error) {
+	if ts.tokens.RefreshToken == "" {
+		return Tokens{}, ErrRefreshTokenInvalid
+	}
+	if ts.deadRefresh == ts.tokens.RefreshToken {
+		return Tokens{}, ts.deadErr
+	}
+
+	tokens, err := ts.service.RefreshToken(ctx, ts.tokens.RefreshToken)
+	if err != nil {
+		if errors.Is(err, ErrRefreshTokenInvalid) {
+			ts.deadRefresh = ts.tokens.RefreshToken
+			ts.deadErr = err
+		}
+		return Tokens{}, err
+	}
+
+	// new tokens are kept even if they weren't saved,
+	// previous ones are disposed anyway
+	ts.tokens = tokens
+
+	return ts.persistLocked(ctx)
+}
+
+// persistLocked passes rotated tokens to onRefresh if they weren't saved yet
+func (ts *RefreshingTokenSource) persistLocked(ctx context.Context) (Tokens, error) {
+	if ts.onRefresh == nil || ts.saved == ts.tokens {
+		return ts.tokens, nil
+	}
+
+	tokens, err := ts.onRefresh(ctx, ts.saved, ts.tokens)
+	if err != nil {
+		return Tokens{}, fmt.Errorf("save refreshed tokens: %w", err)
+	}
+
+	ts.tokens = tokens
+	ts.saved = tokens
+	return tokens, nil
+}
+
+// authorized runs call with access token from the source.
+// If api rejects the token, it is refreshed and call is repeated once.
+func (c *DtfService) authorized(
+	ctx context.Context,
+	ts TokenSource,
+	call func(accessToken string) error,
+) error {
+	if ts == nil {
+		return ErrMissingToken
+	}
+
+	tokens, err := ts.Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = call(tokens.AccessToken)
+	if !errors.Is(err, ErrUnauthorized) && !errors.Is(err, ErrTokenExpired) {
+		return err
+	}
+
+	tokens, refreshErr := ts.Refresh(ctx, tokens)
+	if refreshErr != nil {
+		return errors.Join(err, refreshErr)
+	}
+
+	return call(tokens.AccessToken)
+}
@@ -1,13 +1,15 @@
 package dtfapi
 
 import (
+	"encoding/json"
 	"time"
 )
 
 type Tokens struct {
-	AccessToken      string
-	RefreshToken     string
-	AccessExpiration time.Time
+	AccessToken       string
+	RefreshToken      string
+	AccessExpiration  time.Time
+	RefreshExpiration time.Time
 }
 
 func (t Tokens) IsAccessValid() bool {
@@ -42,10 +44,16 @@ func (dt DataHeader) Type() string {
 	return "header"
 }
 
-type DataList struct {
-	items []string
+type ListType string
+
+const (
+	ListUnordered ListType = "UL"
+	ListOrdered   ListType = "OL"
+)
 
-	// TODO: add list type (ul, li)
+type DataList struct {
+	items    []string
+	ListType ListType
 }
 
 func (dl DataList) Type() string {
@@ -60,21 +68,167 @@ func (dl DataList) Items() []string {
 	return dl.items
 }
 
-// TODO: add other types in future,
-// right now it's fine to have only Text block
-// type DataImage struct {}
+func (dl DataList) IsOrdered() bool {
+	return dl.ListType == ListOrdered
+}
+
+type DataImage struct {
+	Uuid    string
+	Url     string
+	Width   int
+	Height  int
+	Format  string // jpeg, png, gif, etc
+	Caption string
+}
+
+func (di DataImage) Type() string {
+	return "image"
+}
+
+// DataGallery is a media block with several images
+type DataGallery struct {
+	Images []DataImage
+}
+
+func (dg DataGallery) Type() string {
+	return "gallery"
+}
+
+type DataQuote struct {
+	HtmlText string
+	Author   string // subline under the quote, usually author's name
+}
+
+func (dq DataQuote) Type() string {
+	return "quote"
+}
+
+// DataEmbed is an embedded content from other service,
+// such as youtube, telegram, twitter, etc.
+type DataEmbed struct {
+	Service string
+	Url     string
+	Title   string
+}
+
+func (de DataEmbed) Type() string {
+	return "embed"
+}
+
+type DataLink struct {
+	Url         string
+	Title       string
+	Description string
+}
+
+func (dl DataLink) Type() string {
+	return "link"
+}
+
+type DataSpoiler struct {
+	Title    string
+	HtmlText string
+}
+
+func (ds DataSpoiler) Type() string {
+	return "spoiler"
+}
+
+type DataCode struct {
+	Text string
+	Lang string
+}
+
+func (dc DataCode) Type() string {
+	return "code"
+}
+
+type DataDelimiter struct{}
+
+func (dd DataDelimiter) Type() string {
+	return "delimiter"
+}
+
+// DataUnknown keeps blocks which are not supported yet,
+// so they are not lost and can be parsed later.
+type DataUnknown struct {
+	BlockType string
+	Raw       json.RawMessage
+}
+
+func (du DataUnknown) Type() string {
+	return du.BlockType
+}
 
 type BlogPost struct {
-	Id        int
-	Title     string
-	Uri       string
-	Blocks    []DataBlock
-	RepliedTo *int // if not null - it is a reply to that original post
+	Id          int
+	Title       string
+	Uri         string
+	Blocks      []DataBlock
+	RepliedTo   *int // if not null - it is a reply to that original post
+	PublishedAt time.Time
+	Author      UserInfo
+	Subsite     Subsite // where the post is published, may be the author's blog
+	Likes       int
+	Comments    int
+	Views       int
+	Reposts     int
+	CoverUrl    string // empty if post has no cover
 }
 
-// USER Structs
-type UserInfo struct {
+type Subsite struct {
 	Id   int
 	Url  string
 	Name string
 }
+
+// SEARCH Structs
+
+// SearchCursor points to the last seen search result.
+// Zero value means the first page.
+type SearchCursor struct {
+	LastId           int
+	LastSortingValue int64
+}
+
+func (sc SearchCursor) IsZero() bool {
+	return sc.LastId == 0 && sc.LastSortingValue == 0
+}
+
+type SearchPage struct {
+	Posts     []BlogPost
+	Next      SearchCursor
+	Exhausted bool // true when there are no more pages to request
+}
+
+// COMMENT Structs
+
+type Comment struct {
+	Id       int
+	PostId   int
+	Author   UserInfo
+	HtmlText string
+	ReplyTo  int // id of parent comment, 0 if it is a root comment
+	Level    int // nesting level in the thread
+	Date     time.Time
+	Likes    int
+}
+
+type CommentThread struct {
+	Comment Comment
+	Replies []*CommentThread
+}
+
+type CommentsPage struct {
+	Comments  []Comment
+	LastId    int
+	Exhausted bool // true when there are no more pages to request
+}
+
+// USER Structs
+type UserInfo struct {
+	Id        int
+	Url       string
+	Name      string
+	CreatedAt time.Time // zero if unknown
+}